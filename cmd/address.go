@@ -0,0 +1,279 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ---------------- Address standardization & fuzzy search ----------------
+
+// addressParts is a situs address split into its USPS Publication 28 components.
+type addressParts struct {
+	Number  string
+	PreDir  string
+	Name    string
+	Suffix  string
+	PostDir string
+	Unit    string
+}
+
+// suggestionCount is how many ranked candidates are offered when an address has no exact hit.
+const suggestionCount = 10
+
+// minSuggestionScore drops candidates too dissimilar to be worth showing.
+const minSuggestionScore = 0.5
+
+var directionals = map[string]string{
+	"N": "N", "NORTH": "N",
+	"S": "S", "SOUTH": "S",
+	"E": "E", "EAST": "E",
+	"W": "W", "WEST": "W",
+	"NE": "NE", "NORTHEAST": "NE",
+	"NW": "NW", "NORTHWEST": "NW",
+	"SE": "SE", "SOUTHEAST": "SE",
+	"SW": "SW", "SOUTHWEST": "SW",
+}
+
+// streetSuffixes maps common spellings to the USPS standard abbreviation.
+var streetSuffixes = map[string]string{
+	"ALLEY": "ALY", "ALY": "ALY",
+	"AVENUE": "AVE", "AVE": "AVE", "AV": "AVE",
+	"BEND": "BND", "BND": "BND",
+	"BOULEVARD": "BLVD", "BLVD": "BLVD",
+	"CIRCLE": "CIR", "CIR": "CIR",
+	"COURT": "CT", "CT": "CT",
+	"COVE": "CV", "CV": "CV",
+	"CREEK": "CRK", "CRK": "CRK",
+	"CROSSING": "XING", "XING": "XING",
+	"DRIVE": "DR", "DR": "DR",
+	"EXPRESSWAY": "EXPY", "EXPY": "EXPY",
+	"FREEWAY": "FWY", "FWY": "FWY",
+	"GLEN": "GLN", "GLN": "GLN",
+	"GROVE": "GRV", "GRV": "GRV",
+	"HEIGHTS": "HTS", "HTS": "HTS",
+	"HIGHWAY": "HWY", "HWY": "HWY",
+	"HILL": "HL", "HL": "HL",
+	"HOLLOW": "HOLW", "HOLW": "HOLW",
+	"LANDING": "LNDG", "LNDG": "LNDG",
+	"LANE": "LN", "LN": "LN",
+	"LOOP": "LOOP", "LP": "LOOP",
+	"MEADOW": "MDW", "MDW": "MDW",
+	"MEADOWS": "MDWS", "MDWS": "MDWS",
+	"PARKWAY": "PKWY", "PKWY": "PKWY",
+	"PASS": "PASS", "PATH": "PATH",
+	"PLACE": "PL", "PL": "PL",
+	"PLAZA": "PLZ", "PLZ": "PLZ",
+	"POINT": "PT", "PT": "PT",
+	"RIDGE": "RDG", "RDG": "RDG",
+	"ROAD": "RD", "RD": "RD",
+	"ROW": "ROW", "RUN": "RUN",
+	"SQUARE": "SQ", "SQ": "SQ",
+	"STREET": "ST", "ST": "ST", "STR": "ST",
+	"TERRACE": "TER", "TER": "TER",
+	"TRACE": "TRCE", "TRCE": "TRCE",
+	"TRAIL": "TRL", "TRL": "TRL",
+	"VIEW": "VW", "VW": "VW",
+	"VISTA": "VIS", "VIS": "VIS",
+	"WALK": "WALK", "WAY": "WAY",
+}
+
+// unitDesignators are secondary-unit keywords; whatever follows is the unit number.
+var unitDesignators = map[string]bool{
+	"#": true, "APT": true, "APARTMENT": true, "UNIT": true, "STE": true, "SUITE": true,
+	"BLDG": true, "BUILDING": true, "FL": true, "FLOOR": true, "RM": true, "ROOM": true,
+	"SPC": true, "SPACE": true, "LOT": true, "TRLR": true,
+}
+
+var ordinals = map[string]string{
+	"FIRST": "1ST", "SECOND": "2ND", "THIRD": "3RD", "FOURTH": "4TH", "FIFTH": "5TH",
+	"SIXTH": "6TH", "SEVENTH": "7TH", "EIGHTH": "8TH", "NINTH": "9TH", "TENTH": "10TH",
+}
+
+// streetEnded reports whether the street is complete before a unit designator: the last
+// word before it is a street type or directional, or no street type comes after it.
+func streetEnded(before, after []string) bool {
+	last := before[len(before)-1]
+	if streetSuffixes[last] != "" || directionals[last] != "" {
+		return true
+	}
+	for _, t := range after {
+		if streetSuffixes[t] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAddress splits a free-form situs address into standardized components.
+// Street types and directionals are abbreviated, spelled-out ordinals become numeric
+// and any unit designator (APT, UNIT, STE, # …) is reduced to its number.
+func parseAddress(addr string) addressParts {
+	addr = strings.ToUpper(addr)
+	addr = strings.NewReplacer(",", " ", ".", " ", "#", " # ").Replace(addr)
+	tokens := strings.Fields(addr)
+
+	var a addressParts
+	// Unit: everything after the first designator that follows the street. Designator
+	// words also name streets ("7 LOT LN", "100 SPACE CENTER DR"), so one only starts the
+	// unit once a street name has been read and the street has ended: the word before it
+	// is a street type or directional, or no street type follows it.
+	nameStart := 0
+	if len(tokens) > 0 && tokens[0][0] >= '0' && tokens[0][0] <= '9' {
+		nameStart++
+	}
+	if len(tokens) > nameStart+1 && directionals[tokens[nameStart]] != "" {
+		nameStart++
+	}
+	for i := nameStart + 1; i < len(tokens); i++ {
+		if unitDesignators[tokens[i]] && streetEnded(tokens[:i], tokens[i+1:]) {
+			a.Unit = strings.Join(tokens[i+1:], " ")
+			tokens = tokens[:i]
+			break
+		}
+	}
+	if len(tokens) > 0 && tokens[0][0] >= '0' && tokens[0][0] <= '9' {
+		a.Number = tokens[0]
+		tokens = tokens[1:]
+	}
+	// Directionals and suffix are only taken when a street name would remain.
+	if len(tokens) > 1 {
+		if d, ok := directionals[tokens[0]]; ok {
+			a.PreDir = d
+			tokens = tokens[1:]
+		}
+	}
+	if len(tokens) > 1 {
+		if d, ok := directionals[tokens[len(tokens)-1]]; ok {
+			a.PostDir = d
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+	if len(tokens) > 1 {
+		if s, ok := streetSuffixes[tokens[len(tokens)-1]]; ok {
+			a.Suffix = s
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+	for i, t := range tokens {
+		if o, ok := ordinals[t]; ok {
+			tokens[i] = o
+		}
+	}
+	a.Name = strings.Join(tokens, " ")
+	return a
+}
+
+// String renders the components in canonical order, e.g. "123 N MAIN ST # 4".
+func (a addressParts) String() string {
+	var parts []string
+	for _, p := range []string{a.Number, a.PreDir, a.Name, a.Suffix, a.PostDir} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if a.Unit != "" {
+		parts = append(parts, "#", a.Unit)
+	}
+	return strings.Join(parts, " ")
+}
+
+// addressSuggestion is one ranked fuzzy-match candidate.
+type addressSuggestion struct {
+	Address string // normalized index key
+	Score   float64
+}
+
+// suggestAddresses ranks the year's indexed addresses against query and returns the
+// best n. When the query has a house number only addresses on that number are scored,
+// which keeps the scan cheap and matches how people usually mistype addresses.
+func suggestAddresses(query string, y *yearData, n int) []addressSuggestion {
+	q := parseAddress(query)
+	prefix := ""
+	if q.Number != "" {
+		prefix = q.Number + " "
+	}
+
+	var out []addressSuggestion
+	for key := range y.ByAddress {
+		if prefix != "" && !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		score := addressSimilarity(q, parseAddress(key))
+		if score >= minSuggestionScore {
+			out = append(out, addressSuggestion{Address: key, Score: score})
+		}
+	}
+	// No candidates on that house number – the number itself may be the typo.
+	if len(out) == 0 && prefix != "" {
+		for key := range y.ByAddress {
+			score := addressSimilarity(q, parseAddress(key))
+			if score >= minSuggestionScore {
+				out = append(out, addressSuggestion{Address: key, Score: score})
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score == out[j].Score {
+			return out[i].Address < out[j].Address
+		}
+		return out[i].Score > out[j].Score
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// addressSimilarity scores candidate against query in [0,1]. The street name dominates;
+// components the user left out of the query are not held against a candidate.
+func addressSimilarity(q, c addressParts) float64 {
+	match := func(a, b string) float64 {
+		if a == "" || a == b {
+			return 1
+		}
+		return 0
+	}
+	// Compare name+suffix as well so a misspelled street type ("STRET") that was
+	// left inside the name still lines up with the candidate.
+	street := func(a addressParts) string { return strings.TrimSpace(a.Name + " " + a.Suffix) }
+	name := max(stringSimilarity(q.Name, c.Name), stringSimilarity(street(q), street(c)))
+	return 0.55*name +
+		0.25*stringSimilarity(q.Number, c.Number) +
+		0.08*match(q.Suffix, c.Suffix) +
+		0.07*match(q.PreDir+q.PostDir, c.PreDir+c.PostDir) +
+		0.05*match(q.Unit, c.Unit)
+}
+
+// stringSimilarity is 1 minus the normalized Levenshtein distance between a and b.
+func stringSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein returns the edit distance between a and b using two rolling rows.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,70 @@
+package main
+
+import "testing"
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		in   string
+		want addressParts
+	}{
+		{"123 Main Street", addressParts{Number: "123", Name: "MAIN", Suffix: "ST"}},
+		{"123 North Main St.", addressParts{Number: "123", PreDir: "N", Name: "MAIN", Suffix: "ST"}},
+		{"4500 Camp Bowie Blvd West", addressParts{Number: "4500", Name: "CAMP BOWIE", Suffix: "BLVD", PostDir: "W"}},
+		{"200 W Seventh Street", addressParts{Number: "200", PreDir: "W", Name: "7TH", Suffix: "ST"}},
+		{"1010 Houston St Apt 4B", addressParts{Number: "1010", Name: "HOUSTON", Suffix: "ST", Unit: "4B"}},
+		{"1010 Houston St #4B", addressParts{Number: "1010", Name: "HOUSTON", Suffix: "ST", Unit: "4B"}},
+		{"7700 Anglin Dr Lot 12", addressParts{Number: "7700", Name: "ANGLIN", Suffix: "DR", Unit: "12"}},
+		{"5100 Old Decatur Rd Spc 7", addressParts{Number: "5100", Name: "OLD DECATUR", Suffix: "RD", Unit: "7"}},
+		{"500 Oak Lot 12", addressParts{Number: "500", Name: "OAK", Unit: "12"}},
+		// Designator words that are part of the street name.
+		{"7 Lot Ln", addressParts{Number: "7", Name: "LOT", Suffix: "LN"}},
+		{"100 Space Center Dr Unit 4", addressParts{Number: "100", Name: "SPACE CENTER", Suffix: "DR", Unit: "4"}},
+		{"12 N Suite Rd", addressParts{Number: "12", PreDir: "N", Name: "SUITE", Suffix: "RD"}},
+		{"9 Floor St # 2", addressParts{Number: "9", Name: "FLOOR", Suffix: "ST", Unit: "2"}},
+		// A lone street type is the street name, not a suffix.
+		{"100 Trail", addressParts{Number: "100", Name: "TRAIL"}},
+	}
+	for _, tt := range tests {
+		if got := parseAddress(tt.in); got != tt.want {
+			t.Errorf("parseAddress(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeEquivalentSpellings(t *testing.T) {
+	want := "123 N MAIN ST # 4"
+	if got := normalize("7 LOT LN"); got != "7 LOT LN" {
+		t.Errorf("normalize(%q) = %q, want %q", "7 LOT LN", got, "7 LOT LN")
+	}
+	for _, in := range []string{"123 N Main St Unit 4", "123 north main street, apt 4", "123 N. MAIN ST #4"} {
+		if got := normalize(in); got != want {
+			t.Errorf("normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSuggestAddresses(t *testing.T) {
+	y := &yearData{ByAddress: map[string][]string{
+		"123 N MAIN ST":   {"1"},
+		"123 N MAPLE AVE": {"2"},
+		"125 N MAIN ST":   {"3"},
+		"900 ELM ST":      {"4"},
+	}}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"123 N Mian St", "123 N MAIN ST"}, // transposed letters in the name
+		{"123 Main", "123 N MAIN ST"},      // omitted directional and suffix
+		{"901 Elm St", "900 ELM ST"},       // no address on that number: fall back to all
+	}
+	for _, tt := range tests {
+		got := suggestAddresses(tt.query, y, 3)
+		if len(got) == 0 || got[0].Address != tt.want {
+			t.Errorf("suggestAddresses(%q) = %+v, want %q first", tt.query, got, tt.want)
+		}
+	}
+	if got := suggestAddresses("77 Zzyzx Rd", y, 3); len(got) != 0 {
+		t.Errorf("unrelated query got suggestions %+v", got)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Land assemblage ----------------
+
+// Defaults for the assemble command.
+const (
+	defaultAssemblageAcres = 5.0  // combined acreage a cluster must reach
+	defaultAssemblageLot   = 1.0  // smallest parcel considered for a cluster
+	defaultAssemblageGapFt = 20.0 // parcels this far apart still count as adjoining (an alley)
+)
+
+// assemblage is a cluster of adjoining parcels.
+type assemblage struct {
+	Parcels  []ownerParcel // largest first
+	Acres    float64
+	Land     float64 // total appraised land value
+	Geometry bool    // true when every adjacency in the cluster came from parcel polygons
+
+	// The largest group of parcels held under one owner name and under one mailing
+	// address; counts below two mean no parcels are held in common.
+	TopOwner        string
+	TopOwnerCount   int
+	TopMailing      string
+	TopMailingCount int
+}
+
+// perAcre returns the cluster's land value per acre.
+func (a assemblage) perAcre() float64 {
+	if a.Acres == 0 {
+		return 0
+	}
+	return a.Land / a.Acres
+}
+
+// flags describes any parcels the cluster already holds in common.
+func (a assemblage) flags() string {
+	var out []string
+	if a.TopOwnerCount >= 2 {
+		out = append(out, fmt.Sprintf("%d of %d owned by %s", a.TopOwnerCount, len(a.Parcels), a.TopOwner))
+	}
+	if a.TopMailingCount >= 2 && a.TopMailingCount > a.TopOwnerCount {
+		out = append(out, fmt.Sprintf("%d of %d mailed to %s", a.TopMailingCount, len(a.Parcels), a.TopMailing))
+	}
+	return strings.Join(out, "; ")
+}
+
+// unionFind groups candidate indexes into connected clusters.
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	u := make(unionFind, n)
+	for i := range u {
+		u[i] = i
+	}
+	return u
+}
+
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+func (u unionFind) union(i, j int) {
+	u[u.find(i)] = u.find(j)
+}
+
+// findAssemblages clusters the parcels matching f that have at least minLot acres.
+// Two parcels join a cluster when their polygons come within gapFt of each other, or,
+// for parcels without a polygon, when their points are closer than the half-widths of
+// two square lots of their acreage plus gapFt. Clusters of two or more parcels totalling
+// at least minAcres are returned, largest first.
+func findAssemblages(y *yearData, f parcelFilter, minAcres, minLot, gapFt float64) []assemblage {
+	var cands []ownerParcel
+	for _, p := range f.parcels(y) {
+		if acres, ok := parcelAcres(p); ok && acres >= minLot {
+			op := newOwnerParcel(p, false)
+			op.Acres = acres
+			cands = append(cands, op)
+		}
+	}
+	candByAcct := make(map[string]int, len(cands))
+	for i, c := range cands {
+		candByAcct[c.AccountNum] = i
+	}
+
+	uf := newUnionFind(len(cands))
+	pointLinked := make([]bool, len(cands))
+
+	// Parcels with polygons: join lots whose boundaries come within gapFt.
+	for i, c := range cands {
+		shape, ok := parcelGeometry(c.AccountNum)
+		if !ok {
+			continue
+		}
+		shape.neighbours(gapFt, func(acct string) {
+			if j, ok := candByAcct[acct]; ok {
+				uf.union(i, j)
+			}
+		})
+	}
+
+	// Parcels without one: compare points, sized by acreage.
+	halfWidthFt := func(acres float64) float64 { return math.Sqrt(acres*43560) / 2 }
+	maxHalf := 0.0
+	for _, c := range cands {
+		maxHalf = math.Max(maxHalf, halfWidthFt(c.Acres))
+	}
+	for i, c := range cands {
+		_, hasShape := parcelGeometry(c.AccountNum)
+		lat, lon, ok := y.spatial.location(c.AccountNum)
+		if !ok {
+			continue
+		}
+		radius := (halfWidthFt(c.Acres) + maxHalf + gapFt) / 5280
+		for _, hit := range y.spatial.within(lat, lon, radius) {
+			j, ok := candByAcct[hit.Acct]
+			if !ok || j == i {
+				continue
+			}
+			if _, other := parcelGeometry(hit.Acct); hasShape && other {
+				continue // decided by the polygons above
+			}
+			if hit.Miles*5280 <= halfWidthFt(c.Acres)+halfWidthFt(cands[j].Acres)+gapFt {
+				uf.union(i, j)
+				pointLinked[i], pointLinked[j] = true, true
+			}
+		}
+	}
+
+	members := make(map[int][]int)
+	for i := range cands {
+		root := uf.find(i)
+		members[root] = append(members[root], i)
+	}
+	var results []assemblage
+	for _, idx := range members {
+		if len(idx) < 2 {
+			continue
+		}
+		a := assemblage{Geometry: true}
+		owners := make(map[string]int)
+		mailings := make(map[string]int)
+		for _, i := range idx {
+			c := cands[i]
+			a.Parcels = append(a.Parcels, c)
+			a.Acres += c.Acres
+			land, _ := parseDollar(c.LandValue)
+			a.Land += land
+			if pointLinked[i] {
+				a.Geometry = false
+			}
+			if key := strings.Join(ownerTokens(c.OwnerName), " "); key != "" {
+				owners[key]++
+				if owners[key] > a.TopOwnerCount {
+					a.TopOwner, a.TopOwnerCount = c.OwnerName, owners[key]
+				}
+			}
+			if c.MailingKey != "" {
+				mailings[c.MailingKey]++
+				if mailings[c.MailingKey] > a.TopMailingCount {
+					a.TopMailing, a.TopMailingCount = buildOwnerAddress(c.Property), mailings[c.MailingKey]
+				}
+			}
+		}
+		if a.Acres < minAcres {
+			continue
+		}
+		sort.Slice(a.Parcels, func(i, j int) bool { return a.Parcels[i].Acres > a.Parcels[j].Acres })
+		results = append(results, a)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Acres != results[j].Acres {
+			return results[i].Acres > results[j].Acres
+		}
+		return results[i].Parcels[0].SitusAddress < results[j].Parcels[0].SitusAddress
+	})
+	return results
+}
+
+// showAssemblages parses "assemble [min=<acres>] [lot=<acres>] [gap=<feet>] [filters]" and
+// lists clusters of adjoining parcels with their combined acreage and land value.
+func showAssemblages(args string, hist *history) {
+	minAcres, minLot, gapFt := defaultAssemblageAcres, defaultAssemblageLot, defaultAssemblageGapFt
+	f, err := parseFilterArgs(args, func(key, val string) (bool, error) {
+		var dst *float64
+		switch key {
+		case "min":
+			dst = &minAcres
+		case "lot":
+			dst = &minLot
+		case "gap":
+			dst = &gapFt
+		default:
+			return false, nil
+		}
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil || v < 0 {
+			return true, fmt.Errorf("%s=: invalid number %q", key, val)
+		}
+		*dst = v
+		return true, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: assemble [min=<acres>] [lot=<acres>] [gap=<feet>] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+
+	start := time.Now()
+	results := findAssemblages(hist.current(), f, minAcres, minLot, gapFt)
+	fmt.Printf("\nFound %d clusters of ≥%g acres from parcels of ≥%g acres within %g ft of each other in %s (%v)\n",
+		len(results), minAcres, minLot, gapFt, f, time.Since(start).Truncate(time.Millisecond))
+
+	var lines []string
+	var queries []string
+	for n, a := range results {
+		basis := "parcel points"
+		if a.Geometry {
+			basis = "parcel map"
+		}
+		fmt.Printf("\nCluster %d: %d parcels | %.2f acres | Land $%s | $%s/acre | adjacency from %s\n",
+			n+1, len(a.Parcels), a.Acres, formatThousands(a.Land), formatThousands(a.perAcre()), basis)
+		if flags := a.flags(); flags != "" {
+			fmt.Printf("  %s%s%s\n", colorYellow, flags, colorReset)
+		}
+		for _, op := range a.Parcels {
+			land, _ := parseDollar(op.LandValue)
+			line := fmt.Sprintf("#%-3d %-40s | %-30s | %6.2f ac | Land $%11s", n+1, op.SitusAddress, op.OwnerName, op.Acres, formatThousands(land))
+			lines = append(lines, line)
+			queries = append(queries, "acct="+op.AccountNum)
+			fmt.Println("  " + line)
+		}
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
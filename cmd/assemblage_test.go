@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindAssemblagesFromParcelMap(t *testing.T) {
+	saved := parcelShapes
+	defer indexParcelShapes(saved)
+
+	// Three 2-acre lots in a row, an alley's width apart, and a fourth well away.
+	lot := func(acct string, minLon float64) parcelShape {
+		return parcelShape{Acct: acct, polygon: newPolygon([][][2]float64{square(0, minLon, 290, minLon+300, true)})}
+	}
+	indexParcelShapes([]parcelShape{lot("1", 0), lot("2", 315), lot("3", 630), lot("4", 5000)})
+
+	props := map[string]Property{
+		"1": {AccountNum: "1", LandAcres: "2", LandValue: "100000", OwnerName: "SMITH JOHN", OwnerAddress: "1 MAIN ST", OwnerZip: "76107"},
+		"2": {AccountNum: "2", LandAcres: "2", LandValue: "100000", OwnerName: "SMITH, JOHN", OwnerAddress: "1 MAIN ST", OwnerZip: "76107"},
+		"3": {AccountNum: "3", LandAcres: "2", LandValue: "100000", OwnerName: "DOE JANE", OwnerAddress: "9 ELM ST", OwnerZip: "76104"},
+		"4": {AccountNum: "4", LandAcres: "2", LandValue: "100000", OwnerName: "ROE RICHARD", OwnerAddress: "5 OAK ST", OwnerZip: "76104"},
+	}
+	y := &yearData{ByAcct: props, spatial: newGridIndex(props)}
+
+	got := findAssemblages(y, parcelFilter{}, 5, 1, 20)
+	if len(got) != 1 {
+		t.Fatalf("found %d clusters, want 1", len(got))
+	}
+	a := got[0]
+	if len(a.Parcels) != 3 || a.Acres != 6 || a.Land != 300000 || !a.Geometry {
+		t.Errorf("cluster = %d parcels, %g acres, $%g land, geometry %v; want 3, 6, $300000, true", len(a.Parcels), a.Acres, a.Land, a.Geometry)
+	}
+	if a.TopOwnerCount != 2 || a.TopMailingCount != 2 {
+		t.Errorf("owner count %d, mailing count %d; want 2 and 2", a.TopOwnerCount, a.TopMailingCount)
+	}
+
+	// Lots 15 ft apart are not joined when the allowed gap is smaller.
+	if got := findAssemblages(y, parcelFilter{}, 5, 1, 10); len(got) != 0 {
+		t.Errorf("with a 10 ft gap found %d clusters, want 0", len(got))
+	}
+}
+
+func TestFindAssemblagesFromParcelPoints(t *testing.T) {
+	saved := parcelShapes
+	defer indexParcelShapes(saved)
+	indexParcelShapes(nil)
+
+	// No parcel map: two 1-acre lots (about 209 ft square) with points 200 ft apart touch,
+	// a third 1,000 ft further east does not.
+	const ftLon = 1 / (364000 * 0.8411) // degrees of longitude per foot near 32.7°N
+	at := func(acct string, ft float64) Property {
+		return Property{AccountNum: acct, LandAcres: "1", LandValue: "50000",
+			Latitude: "32.7", Longitude: fmt.Sprintf("%.7f", -97.3+ft*ftLon)}
+	}
+	props := map[string]Property{"1": at("1", 0), "2": at("2", 200), "3": at("3", 1200)}
+	y := &yearData{ByAcct: props, spatial: newGridIndex(props)}
+
+	got := findAssemblages(y, parcelFilter{}, 1.5, 0.5, 0)
+	if len(got) != 1 {
+		t.Fatalf("found %d clusters, want 1", len(got))
+	}
+	a := got[0]
+	if len(a.Parcels) != 2 || a.Acres != 2 || a.Geometry {
+		t.Errorf("cluster = %d parcels, %g acres, geometry %v; want 2, 2, false", len(a.Parcels), a.Acres, a.Geometry)
+	}
+	for _, p := range a.Parcels {
+		if p.AccountNum == "3" {
+			t.Errorf("parcel 3, 1,000 ft away, joined the cluster")
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Year-over-year change feed ----------------
+
+// defaultValueJump is the fractional change in total value reported as a jump.
+const defaultValueJump = 0.25
+
+// changeKind classifies one entry in the change feed.
+type changeKind int
+
+const (
+	changeTransfer changeKind = iota
+	changeNew
+	changeRemoved
+	changeValueJump
+)
+
+var changeKindNames = []string{"Transfer", "New", "Removed", "Value"}
+
+func (k changeKind) String() string { return changeKindNames[k] }
+
+// parcelChange is one difference between two years for a single account.
+type parcelChange struct {
+	Kind   changeKind
+	Acct   string
+	Prop   Property // the newer record, or the older one for removed parcels
+	Change float64  // fractional value change for changeValueJump
+	Detail string
+}
+
+// yearRange selects the two years a year-over-year command compares. Zero values mean
+// the active year and the loaded year before To.
+type yearRange struct {
+	From, To int
+}
+
+// option handles the from= and to= arguments for parseFilterArgs.
+func (r *yearRange) option(key, val string) (bool, error) {
+	if key != "from" && key != "to" {
+		return false, nil
+	}
+	year, err := strconv.Atoi(val)
+	if err != nil {
+		return true, fmt.Errorf("%s=: invalid year %q", key, val)
+	}
+	if key == "from" {
+		r.From = year
+	} else {
+		r.To = year
+	}
+	return true, nil
+}
+
+// resolve looks both years up, reporting why when they cannot be compared.
+func (r yearRange) resolve(hist *history) (from, to *yearData, ok bool) {
+	toYear := r.To
+	if toYear == 0 {
+		toYear = hist.Active
+	}
+	if to, ok = hist.ByYear[toYear]; !ok {
+		fmt.Printf("Year %d not loaded; available years: %s\n", toYear, formatYears(hist.Years))
+		return nil, nil, false
+	}
+	from = hist.prior(toYear)
+	if r.From != 0 {
+		if from, ok = hist.ByYear[r.From]; !ok {
+			fmt.Printf("Year %d not loaded; available years: %s\n", r.From, formatYears(hist.Years))
+			return nil, nil, false
+		}
+	}
+	if from == nil || from.Year >= to.Year {
+		fmt.Printf("Need an earlier year to compare %d against; available years: %s\n", to.Year, formatYears(hist.Years))
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+// findChanges compares two years account by account and returns every transfer, new
+// parcel, removed parcel and value jump among the parcels matching f in either year.
+// Transfers are newest deed first, value jumps largest first.
+func findChanges(from, to *yearData, f parcelFilter, jump float64) []parcelChange {
+	accts := make(map[string]bool)
+	for _, p := range f.parcels(to) {
+		accts[p.AccountNum] = true
+	}
+	for _, p := range f.parcels(from) {
+		accts[p.AccountNum] = true
+	}
+
+	var changes []parcelChange
+	for acct := range accts {
+		cur, inTo := to.ByAcct[acct]
+		old, inFrom := from.ByAcct[acct]
+		switch {
+		case !inFrom:
+			changes = append(changes, parcelChange{Kind: changeNew, Acct: acct, Prop: cur,
+				Detail: fmt.Sprintf("%s | $%s", cur.OwnerName, cur.TotalValue)})
+			continue
+		case !inTo:
+			detail := "account retired"
+			// A new account at the same address usually means a replat or merger.
+			for _, p := range to.lookupAddress(normalize(old.SitusAddress)) {
+				if _, existed := from.ByAcct[p.AccountNum]; !existed {
+					detail = "replaced by acct " + p.AccountNum
+					break
+				}
+			}
+			changes = append(changes, parcelChange{Kind: changeRemoved, Acct: acct, Prop: old, Detail: detail})
+			continue
+		}
+
+		ownerChanged := strings.Join(ownerTokens(cur.OwnerName), " ") != strings.Join(ownerTokens(old.OwnerName), " ")
+		deedChanged := strings.TrimSpace(cur.DeedDate) != "" && strings.TrimSpace(cur.DeedDate) != strings.TrimSpace(old.DeedDate)
+		if ownerChanged || deedChanged {
+			detail := fmt.Sprintf("%s -> %s", old.OwnerName, cur.OwnerName)
+			if !ownerChanged {
+				detail = fmt.Sprintf("%s (new deed, same owner)", cur.OwnerName)
+			}
+			if deedChanged {
+				detail += " | deed " + cur.DeedDate
+			}
+			changes = append(changes, parcelChange{Kind: changeTransfer, Acct: acct, Prop: cur, Detail: detail})
+		}
+
+		curVal, ok1 := parseDollar(cur.TotalValue)
+		oldVal, ok2 := parseDollar(old.TotalValue)
+		if ok1 && ok2 && oldVal > 0 {
+			if pct := (curVal - oldVal) / oldVal; math.Abs(pct) >= jump {
+				changes = append(changes, parcelChange{Kind: changeValueJump, Acct: acct, Prop: cur, Change: pct,
+					Detail: fmt.Sprintf("$%.0f -> $%.0f (%+.0f%%)", oldVal, curVal, pct*100)})
+			}
+		}
+	}
+
+	deed := func(c parcelChange) time.Time {
+		t, _ := time.Parse("01-02-2006", c.Prop.DeedDate)
+		return t
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		switch a.Kind {
+		case changeTransfer:
+			if da, db := deed(a), deed(b); !da.Equal(db) {
+				return da.After(db)
+			}
+		case changeValueJump:
+			if math.Abs(a.Change) != math.Abs(b.Change) {
+				return math.Abs(a.Change) > math.Abs(b.Change)
+			}
+		}
+		return a.Prop.SitusAddress < b.Prop.SitusAddress
+	})
+	return changes
+}
+
+// showChanges parses "changes [from=YYYY] [to=YYYY] [jump=0.25] [filters]", compares the
+// two years and lists the changes. By default the active year is compared with the
+// year before it.
+func showChanges(args string, hist *history) {
+	var years yearRange
+	jump := defaultValueJump
+	f, err := parseFilterArgs(args, func(key, val string) (bool, error) {
+		if key != "jump" {
+			return years.option(key, val)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(val, "%"), 64)
+		if err != nil || v <= 0 {
+			return true, fmt.Errorf("jump=: invalid fraction %q", val)
+		}
+		if strings.HasSuffix(val, "%") {
+			v /= 100
+		}
+		jump = v
+		return true, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: changes [from=YYYY] [to=YYYY] [jump=<fraction>] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+	from, to, ok := years.resolve(hist)
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	changes := findChanges(from, to, f, jump)
+	counts := make([]int, len(changeKindNames))
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	fmt.Printf("\nChanges %d -> %d in %s (%v)\n", from.Year, to.Year, f, time.Since(start).Truncate(time.Millisecond))
+	fmt.Printf("  Transfers: %d | New parcels: %d | Removed parcels: %d | Value jumps ≥ %.0f%%: %d\n",
+		counts[changeTransfer], counts[changeNew], counts[changeRemoved], jump*100, counts[changeValueJump])
+
+	var lines []string
+	var queries []string
+	for _, c := range changes {
+		line := fmt.Sprintf("%-8s | %-40s | %s", c.Kind, c.Prop.SitusAddress, c.Detail)
+		lines = append(lines, line)
+		queries = append(queries, "acct="+c.Acct)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
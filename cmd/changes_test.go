@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFindChanges(t *testing.T) {
+	from := &yearData{Year: 2024, ByAcct: map[string]Property{
+		"sold":    {AccountNum: "sold", SitusAddress: "1 A ST", OwnerName: "SMITH JOHN", DeedDate: "01-05-2015", TotalValue: "100000"},
+		"redeed":  {AccountNum: "redeed", SitusAddress: "2 A ST", OwnerName: "DOE JANE", DeedDate: "03-01-2010", TotalValue: "100000"},
+		"renamed": {AccountNum: "renamed", SitusAddress: "3 A ST", OwnerName: "SMITH, JOHN", DeedDate: "03-01-2010", TotalValue: "100000"},
+		"jump":    {AccountNum: "jump", SitusAddress: "4 A ST", OwnerName: "ROE RICHARD", DeedDate: "03-01-2010", TotalValue: "100000"},
+		"steady":  {AccountNum: "steady", SitusAddress: "5 A ST", OwnerName: "ROE RICHARD", DeedDate: "03-01-2010", TotalValue: "100000"},
+		"gone":    {AccountNum: "gone", SitusAddress: "6 A ST", OwnerName: "ROE RICHARD", TotalValue: "100000"},
+	}}
+	to := &yearData{Year: 2025, ByAcct: map[string]Property{
+		"sold":    {AccountNum: "sold", SitusAddress: "1 A ST", OwnerName: "ACME HOMES LLC", DeedDate: "06-01-2024", TotalValue: "110000"},
+		"redeed":  {AccountNum: "redeed", SitusAddress: "2 A ST", OwnerName: "DOE JANE", DeedDate: "02-01-2024", TotalValue: "100000"},
+		"renamed": {AccountNum: "renamed", SitusAddress: "3 A ST", OwnerName: "SMITH JOHN", DeedDate: "03-01-2010", TotalValue: "100000"},
+		"jump":    {AccountNum: "jump", SitusAddress: "4 A ST", OwnerName: "ROE RICHARD", DeedDate: "03-01-2010", TotalValue: "150000"},
+		"steady":  {AccountNum: "steady", SitusAddress: "5 A ST", OwnerName: "ROE RICHARD", DeedDate: "03-01-2010", TotalValue: "110000"},
+		"new":     {AccountNum: "new", SitusAddress: "7 A ST", OwnerName: "NEW OWNER", TotalValue: "90000"},
+	}}
+
+	got := make(map[changeKind][]string)
+	for _, c := range findChanges(from, to, parcelFilter{}, 0.25) {
+		got[c.Kind] = append(got[c.Kind], c.Acct)
+	}
+	want := map[changeKind][]string{
+		changeTransfer:  {"redeed", "sold"},
+		changeNew:       {"new"},
+		changeRemoved:   {"gone"},
+		changeValueJump: {"jump"},
+	}
+	for k := range got {
+		sort.Strings(got[k])
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findChanges = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Comparable-property (comps) engine ----------------
+
+// defaultCompCount is how many comps are returned when the command does not say.
+const defaultCompCount = 5
+
+// Candidate comps are the compCandidatePool nearest parcels within compSearchMiles.
+const (
+	compCandidatePool = 200
+	compSearchMiles   = 1.0
+)
+
+// compWeights scale each attribute's normalized difference when scoring similarity.
+// Each difference is first divided by its "one unit of dissimilarity" scale below.
+var compWeights = struct {
+	Distance, Area, Beds, Baths, Age, Quality, Condition float64
+}{Distance: 2, Area: 2, Beds: 0.5, Baths: 0.5, Age: 1, Quality: 1, Condition: 1}
+
+const (
+	compDistanceScale = 0.5  // miles
+	compAreaScale     = 0.25 // fraction of subject living area
+	compAgeScale      = 15   // years
+)
+
+// Adjustment rates used to bring each comp in line with the subject.
+const (
+	adjAreaFactor   = 0.5    // share of the comps' median $/sqft applied per sqft of difference
+	adjPerBedroom   = 5000.0 // dollars
+	adjPerBathroom  = 7500.0 // dollars
+	adjAgePerYear   = 0.005  // share of comp value per year of age difference
+	adjPerGradeStep = 0.05   // share of comp value per quality/condition step
+)
+
+// gradeRanks orders TAD quality and condition descriptions from worst to best.
+var gradeRanks = map[string]int{
+	"UNSOUND":   0,
+	"POOR":      1,
+	"FAIR":      2,
+	"AVERAGE":   3,
+	"GOOD":      4,
+	"VERY GOOD": 5,
+	"EXCELLENT": 6,
+}
+
+// gradeRank maps a quality or condition description onto gradeRanks.
+func gradeRank(s string) (int, bool) {
+	r, ok := gradeRanks[strings.ToUpper(strings.Join(strings.Fields(s), " "))]
+	return r, ok
+}
+
+// compAdjustment is one line of the adjustment grid for a comp.
+type compAdjustment struct {
+	Label  string
+	Amount float64
+}
+
+// compResult is a comparable parcel with its similarity score and adjustments.
+type compResult struct {
+	Property
+	Miles       float64
+	Score       float64 // weighted dissimilarity; lower is more similar
+	Value       float64 // comp's appraised total value
+	Adjustments []compAdjustment
+	Adjusted    float64 // Value plus all adjustments
+}
+
+// compEstimate is the adjusted value conclusion for a subject.
+type compEstimate struct {
+	Comps    []compResult
+	Estimate float64 // similarity-weighted mean of the adjusted comp values
+	Low      float64
+	High     float64
+}
+
+// compFeatures holds the parsed attributes used for matching and adjusting.
+type compFeatures struct {
+	area, beds, baths, year float64
+	quality, condition      int
+	hasQuality, hasCond     bool
+}
+
+func parseCompFeatures(p Property) (compFeatures, bool) {
+	var f compFeatures
+	var ok bool
+	if f.area, ok = parseDollar(p.LivingArea); !ok || f.area <= 0 {
+		return f, false
+	}
+	f.beds, _ = parseDollar(p.NumBedrooms)
+	f.baths, _ = parseDollar(p.NumBathrooms)
+	if y, err := strconv.Atoi(strings.TrimSpace(p.YearBuilt)); err == nil {
+		f.year = float64(y)
+	}
+	f.quality, f.hasQuality = gradeRank(p.Quality)
+	f.condition, f.hasCond = gradeRank(p.Condition)
+	return f, true
+}
+
+// findComps returns the k parcels most similar to subject near it, each adjusted toward
+// the subject, and a similarity-weighted value estimate.
+func findComps(subject Property, y *yearData, k int) (compEstimate, error) {
+	sf, ok := parseCompFeatures(subject)
+	if !ok {
+		return compEstimate{}, fmt.Errorf("subject has no living area; comps need an improved parcel")
+	}
+	lat, lon, ok := y.spatial.location(subject.AccountNum)
+	if !ok {
+		return compEstimate{}, fmt.Errorf("subject has no coordinates")
+	}
+
+	var comps []compResult
+	for _, hit := range y.spatial.nearest(lat, lon, compCandidatePool, compSearchMiles) {
+		if hit.Acct == subject.AccountNum {
+			continue
+		}
+		p := y.ByAcct[hit.Acct]
+		cf, ok := parseCompFeatures(p)
+		if !ok {
+			continue
+		}
+		val, ok := parseDollar(p.TotalValue)
+		if !ok || val <= 0 {
+			continue
+		}
+		comps = append(comps, compResult{
+			Property: p,
+			Miles:    hit.Miles,
+			Score:    compScore(sf, cf, hit.Miles),
+			Value:    val,
+		})
+	}
+	if len(comps) == 0 {
+		return compEstimate{}, fmt.Errorf("no improved parcels within %.1f mi", compSearchMiles)
+	}
+	sort.Slice(comps, func(i, j int) bool { return comps[i].Score < comps[j].Score })
+	if len(comps) > k {
+		comps = comps[:k]
+	}
+
+	// Area adjustments are priced off the selected comps' median $/sqft.
+	var perSqft []float64
+	for _, c := range comps {
+		area, _ := parseDollar(c.LivingArea)
+		perSqft = append(perSqft, c.Value/area)
+	}
+	sort.Float64s(perSqft)
+	areaRate := perSqft[len(perSqft)/2] * adjAreaFactor
+
+	est := compEstimate{Low: math.MaxFloat64}
+	var weightSum float64
+	for i := range comps {
+		c := &comps[i]
+		cf, _ := parseCompFeatures(c.Property)
+		c.Adjustments = compAdjustments(subject, sf, c.Property, cf, c.Value, areaRate)
+		c.Adjusted = c.Value
+		for _, a := range c.Adjustments {
+			c.Adjusted += a.Amount
+		}
+		w := 1 / (1 + c.Score)
+		est.Estimate += w * c.Adjusted
+		weightSum += w
+		est.Low = min(est.Low, c.Adjusted)
+		est.High = max(est.High, c.Adjusted)
+	}
+	est.Estimate /= weightSum
+	est.Comps = comps
+	return est, nil
+}
+
+// compScore is the weighted sum of squared normalized differences between subject and comp.
+// Quality and condition only count when both parcels report them.
+func compScore(s, c compFeatures, miles float64) float64 {
+	sq := func(x float64) float64 { return x * x }
+	score := compWeights.Distance*sq(miles/compDistanceScale) +
+		compWeights.Area*sq((c.area-s.area)/(s.area*compAreaScale)) +
+		compWeights.Beds*sq(c.beds-s.beds) +
+		compWeights.Baths*sq(c.baths-s.baths)
+	if s.year > 0 && c.year > 0 {
+		score += compWeights.Age * sq((c.year-s.year)/compAgeScale)
+	}
+	if s.hasQuality && c.hasQuality {
+		score += compWeights.Quality * sq(float64(c.quality-s.quality))
+	}
+	if s.hasCond && c.hasCond {
+		score += compWeights.Condition * sq(float64(c.condition-s.condition))
+	}
+	return score
+}
+
+// compAdjustments prices each difference between comp and subject. A positive amount
+// means the subject is superior, so the comp's value is adjusted upward.
+func compAdjustments(subject Property, s compFeatures, comp Property, c compFeatures, value, areaRate float64) []compAdjustment {
+	var adj []compAdjustment
+	add := func(label string, amount float64) {
+		if amount != 0 {
+			adj = append(adj, compAdjustment{Label: label, Amount: amount})
+		}
+	}
+	add(fmt.Sprintf("Living area (%+.0f sf)", s.area-c.area), (s.area-c.area)*areaRate)
+	add(fmt.Sprintf("Bedrooms (%+.0f)", s.beds-c.beds), (s.beds-c.beds)*adjPerBedroom)
+	add(fmt.Sprintf("Bathrooms (%+.1f)", s.baths-c.baths), (s.baths-c.baths)*adjPerBathroom)
+	if s.year > 0 && c.year > 0 {
+		add(fmt.Sprintf("Year built (%+.0f yr)", s.year-c.year), (s.year-c.year)*adjAgePerYear*value)
+	}
+	if s.hasQuality && c.hasQuality {
+		add(fmt.Sprintf("Quality (%s vs %s)", subject.Quality, comp.Quality), float64(s.quality-c.quality)*adjPerGradeStep*value)
+	}
+	if s.hasCond && c.hasCond {
+		add(fmt.Sprintf("Condition (%s vs %s)", subject.Condition, comp.Condition), float64(s.condition-c.condition)*adjPerGradeStep*value)
+	}
+	sLand, ok1 := parseDollar(subject.LandValue)
+	cLand, ok2 := parseDollar(comp.LandValue)
+	if ok1 && ok2 {
+		add("Site (land value)", sLand-cLand)
+	}
+	return adj
+}
+
+// showComps resolves the subject from "comps [k=N] <address|acct=...>" arguments in the
+// active year, prints the adjustment grid for each comp and the value conclusion.
+func showComps(args string, hist *history) {
+	k := defaultCompCount
+	fields := strings.Fields(args)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "k=") {
+		if n, err := strconv.Atoi(strings.TrimPrefix(fields[0], "k=")); err == nil && n > 0 {
+			k = n
+		}
+		fields = fields[1:]
+	}
+	query := strings.Join(fields, " ")
+	if query == "" {
+		fmt.Println("Usage: comps [k=N] <address | acct=Account_Num>")
+		return
+	}
+
+	cur := hist.current()
+	var subject Property
+	if acct, ok := accountQuery(query); ok {
+		p, found := cur.ByAcct[acct]
+		if !found {
+			fmt.Printf("No %d property found for account: %s\n", cur.Year, acct)
+			return
+		}
+		subject = p
+	} else {
+		props := cur.lookupAddress(normalize(query))
+		switch len(props) {
+		case 0:
+			fmt.Printf("No %d property found for address: %s\n", cur.Year, query)
+			return
+		case 1:
+			subject = props[0]
+		default:
+			fmt.Printf("%d parcels share the address %s; rerun with acct=<Account_Num>:\n", len(props), query)
+			for _, p := range props {
+				fmt.Printf("  acct=%s  %s  %s sf\n", p.AccountNum, p.OwnerName, p.LivingArea)
+			}
+			return
+		}
+	}
+
+	est, err := findComps(subject, cur, k)
+	if err != nil {
+		fmt.Printf("Cannot build comps for %s: %v\n", subject.SitusAddress, err)
+		return
+	}
+
+	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("Subject           : %s (acct %s)\n", subject.SitusAddress, subject.AccountNum)
+	fmt.Printf("                    %s sf | %s bd / %s ba | built %s | Q: %s | C: %s | appraised $%s\n",
+		subject.LivingArea, subject.NumBedrooms, subject.NumBathrooms, subject.YearBuilt, subject.Quality, subject.Condition, subject.TotalValue)
+
+	var lines []string
+	var queries []string
+	for i, c := range est.Comps {
+		fmt.Printf("\nComp %d            : %s (%.2f mi, similarity %.2f)\n", i+1, c.SitusAddress, c.Miles, 1/(1+c.Score))
+		fmt.Printf("                    %s sf | %s bd / %s ba | built %s | Q: %s | C: %s\n",
+			c.LivingArea, c.NumBedrooms, c.NumBathrooms, c.YearBuilt, c.Quality, c.Condition)
+		fmt.Printf("  Appraised value : $%12.0f\n", c.Value)
+		gross := 0.0
+		for _, a := range c.Adjustments {
+			fmt.Printf("  %-30s: %+12.0f\n", a.Label, a.Amount)
+			gross += math.Abs(a.Amount)
+		}
+		fmt.Printf("  Adjusted value  : $%12.0f (gross adjustment %.0f%%)\n", c.Adjusted, 100*gross/c.Value)
+
+		line := fmt.Sprintf("%-40s | %.2f mi | sim %.2f | $%10.0f -> $%10.0f", c.SitusAddress, c.Miles, 1/(1+c.Score), c.Value, c.Adjusted)
+		lines = append(lines, line)
+		queries = append(queries, "acct="+c.AccountNum)
+	}
+	fmt.Printf("\nEstimated value   : $%.0f (range $%.0f – $%.0f from %d comps)\n", est.Estimate, est.Low, est.High, len(est.Comps))
+	fmt.Println(strings.Repeat("-", 80))
+
+	fmt.Println("Use ↑/↓ and Enter for comp details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ---------------- Yearly dataset registry ----------------
+
+// datasetDir is scanned at startup for yearly TAD exports.
+var datasetDir = "data"
+
+// TAD names its exports PropertyData[_R]_<year>.txt and PropertyDataSupplemental[_R]_<year>.txt.
+var (
+	primaryPattern      = regexp.MustCompile(`^PropertyData(?:_R)?_(\d{4})\.txt$`)
+	supplementalPattern = regexp.MustCompile(`^PropertyDataSupplemental(?:_R)?_(\d{4})\.txt$`)
+)
+
+// datasetFiles lists the export files discovered for one tax year.
+type datasetFiles struct {
+	Year         int
+	Primary      string
+	Supplemental string // empty when no supplemental export exists for the year
+}
+
+// yearData holds one tax year's merged primary + supplemental records.
+type yearData struct {
+	Year      int
+	ByAcct    map[string]Property // keyed by account number (the primary key)
+	ByAddress map[string][]string // normalized situs address -> every account at that address
+
+	spatial    *gridIndex // built after loading; not part of the snapshot
+	zoningOnce sync.Once  // guards ensureZoning
+}
+
+// history is every loaded year keyed by tax year. Active is the year treated as
+// "current" by lookups and filters; comparisons are made against the year before it.
+type history struct {
+	Years  []int // ascending
+	ByYear map[int]*yearData
+	Active int
+}
+
+// discoverDatasets scans dir for yearly exports and returns them in ascending year
+// order. A year is only included if its primary file exists.
+func discoverDatasets(dir string) ([]datasetFiles, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byYear := make(map[int]*datasetFiles)
+	get := func(year int) *datasetFiles {
+		f, ok := byYear[year]
+		if !ok {
+			f = &datasetFiles{Year: year}
+			byYear[year] = f
+		}
+		return f
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if m := primaryPattern.FindStringSubmatch(name); m != nil {
+			year, _ := strconv.Atoi(m[1])
+			get(year).Primary = filepath.Join(dir, name)
+		} else if m := supplementalPattern.FindStringSubmatch(name); m != nil {
+			year, _ := strconv.Atoi(m[1])
+			get(year).Supplemental = filepath.Join(dir, name)
+		}
+	}
+
+	var found []datasetFiles
+	for _, f := range byYear {
+		if f.Primary == "" {
+			fmt.Fprintf(os.Stderr, "warning: %d supplemental file has no primary export; skipping\n", f.Year)
+			continue
+		}
+		if f.Supplemental == "" {
+			fmt.Fprintf(os.Stderr, "warning: %d has no supplemental export; condition, quality, depreciation, sale date, situs zip and coordinates will be blank\n", f.Year)
+		} else if ok, err := hasColumn(f.Supplemental, "SitusZip"); err == nil && !ok {
+			// The primary export has no situs zip; zip= and owner occupancy depend on this column.
+			fmt.Fprintf(os.Stderr, "warning: %d supplemental export has no SitusZip column; zip= filters and owner occupancy will not match\n", f.Year)
+		}
+		found = append(found, *f)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no PropertyData_<year>.txt exports found in %s", dir)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Year < found[j].Year })
+	return found, nil
+}
+
+// loadDatasets discovers every yearly export, loads each year (merging its supplemental
+// file by Account Number) and returns them as a history with the newest year active.
+// When a snapshot matching the current source files exists it is used instead of re-parsing.
+func loadDatasets() (*history, error) {
+	files, err := discoverDatasets(datasetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []string
+	for _, f := range files {
+		sources = append(sources, f.Primary)
+		if f.Supplemental != "" {
+			sources = append(sources, f.Supplemental)
+		}
+	}
+	stamps, err := stampSources(sources)
+	if err != nil {
+		return nil, err
+	}
+	if snap, ok := readSnapshot(snapshotFile, stamps); ok {
+		return newHistory(snap.Years), nil
+	}
+
+	years := make(map[int]*yearData, len(files))
+	for _, f := range files {
+		yd, err := loadYear(f)
+		if err != nil {
+			return nil, err
+		}
+		years[f.Year] = yd
+	}
+
+	snap := &datasetSnapshot{
+		Version: snapshotVersion,
+		Sources: stamps,
+		Years:   years,
+	}
+	if err := writeSnapshot(snapshotFile, snap); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not write dataset snapshot: %v\n", err)
+	}
+
+	return newHistory(years), nil
+}
+
+func newHistory(years map[int]*yearData) *history {
+	h := &history{ByYear: years}
+	for y := range years {
+		h.Years = append(h.Years, y)
+	}
+	sort.Ints(h.Years)
+	h.Active = h.Years[len(h.Years)-1]
+	for _, yd := range years {
+		yd.spatial = newGridIndex(yd.ByAcct)
+	}
+	return h
+}
+
+// loadYear reads one year's primary file and merges its supplemental file (if any).
+func loadYear(f datasetFiles) (*yearData, error) {
+	byAcct := make(map[string]Property)
+	var mu sync.Mutex
+	if err := readFile(f.Primary, func(record map[string]string) {
+		prop := propertyFromPrimary(record)
+		mu.Lock()
+		byAcct[prop.AccountNum] = prop
+		mu.Unlock()
+	}); err != nil {
+		return nil, err
+	}
+
+	if f.Supplemental != "" {
+		if err := readFile(f.Supplemental, func(record map[string]string) {
+			acct := record["AccountNumber"]
+			mu.Lock()
+			defer mu.Unlock()
+			prop, ok := byAcct[acct]
+			if !ok {
+				// Only merge if we already have the base record.
+				return
+			}
+			mergeSupplemental(&prop, record)
+			byAcct[acct] = prop
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &yearData{
+		Year:      f.Year,
+		ByAcct:    byAcct,
+		ByAddress: addressIndex(byAcct),
+	}, nil
+}
+
+// propertyFromPrimary maps a primary-file record onto a Property.
+func propertyFromPrimary(record map[string]string) Property {
+	return Property{
+		AccountNum:       record["Account_Num"],
+		SitusAddress:     record["Situs_Address"],
+		OwnerName:        record["Owner_Name"],
+		OwnerAddress:     record["Owner_Address"],
+		OwnerCityState:   record["Owner_CityState"],
+		OwnerZip:         record["Owner_Zip"],
+		Subdivision:      record["SubdivisionName"],
+		County:           record["County"],
+		City:             record["City"],
+		SchoolDistrict:   record["School"],
+		LandValue:        record["Land_Value"],
+		ImprovementValue: record["Improvement_Value"],
+		TotalValue:       record["Total_Value"],
+
+		DeedDate:     record["Deed_Date"],
+		ARBIndicator: record["ARB_Indicator"],
+
+		YearBuilt:    record["Year_Built"],
+		LivingArea:   record["Living_Area"],
+		NumBedrooms:  record["Num_Bedrooms"],
+		NumBathrooms: record["Num_Bathrooms"],
+
+		PropertyClass: record["Property_Class"],
+		StateUseCode:  record["State_Use_Code"],
+
+		LandAcres: record["Land_Acres"],
+		LandSqFt:  record["Land_SqFt"],
+	}
+}
+
+// mergeSupplemental copies the supplemental-file fields onto prop.
+func mergeSupplemental(prop *Property, record map[string]string) {
+	prop.Latitude = record["Latitude"]
+	prop.Longitude = record["Longitude"]
+	prop.Quality = record["Quality"]
+
+	prop.LastSaleDate = record["LastSaleDate"]
+	prop.Condition = record["Condition"]
+	prop.DepreciationPercent = record["DepreciationPercent"]
+
+	if sub := record["SubdivisionName"]; sub != "" {
+		prop.Subdivision = sub
+	}
+	if zip := record["SitusZip"]; zip != "" {
+		prop.SitusZip = zip
+	}
+	prop.SiteClassCd = record["SiteClassCd"]
+	prop.SiteClassDescr = record["SiteClassDescr"]
+	prop.LandUseCode = record["LandUseCode"]
+}
+
+// addressIndex maps each normalized situs address to the accounts located there.
+// Condos, duplex units and parcels sharing a situs address all keep their own entry;
+// each list is sorted so pickers show units in a stable order.
+func addressIndex(byAcct map[string]Property) map[string][]string {
+	idx := make(map[string][]string, len(byAcct))
+	for acct, prop := range byAcct {
+		addr := normalize(prop.SitusAddress)
+		idx[addr] = append(idx[addr], acct)
+	}
+	for _, accts := range idx {
+		sort.Strings(accts)
+	}
+	return idx
+}
+
+// current returns the active year's data.
+func (h *history) current() *yearData {
+	y := h.ByYear[h.Active]
+	y.ensureZoning()
+	return y
+}
+
+// prior returns the nearest loaded year before year, or nil if there is none.
+func (h *history) prior(year int) *yearData {
+	for i := len(h.Years) - 1; i >= 0; i-- {
+		if h.Years[i] < year {
+			return h.ByYear[h.Years[i]]
+		}
+	}
+	return nil
+}
+
+// setActive makes year the current year. It returns false if year is not loaded.
+func (h *history) setActive(year int) bool {
+	if _, ok := h.ByYear[year]; !ok {
+		return false
+	}
+	h.Active = year
+	return true
+}
+
+// findByAccount looks the account up in the active year first, then in the remaining
+// years from newest to oldest.
+func (h *history) findByAccount(acct string) (Property, int, bool) {
+	for _, year := range h.searchOrder() {
+		y := h.ByYear[year]
+		if _, ok := y.ByAcct[acct]; ok {
+			y.ensureZoning()
+			return y.ByAcct[acct], year, true
+		}
+	}
+	return Property{}, 0, false
+}
+
+// findByAddress returns every parcel at the normalized address, searching the active
+// year first and then the remaining years from newest to oldest.
+func (h *history) findByAddress(norm string) ([]Property, int, bool) {
+	for _, year := range h.searchOrder() {
+		y := h.ByYear[year]
+		if len(y.ByAddress[norm]) > 0 {
+			y.ensureZoning()
+			return y.lookupAddress(norm), year, true
+		}
+	}
+	return nil, 0, false
+}
+
+// searchOrder lists the active year followed by the other years, newest first.
+func (h *history) searchOrder() []int {
+	order := []int{h.Active}
+	for i := len(h.Years) - 1; i >= 0; i-- {
+		if h.Years[i] != h.Active {
+			order = append(order, h.Years[i])
+		}
+	}
+	return order
+}
+
+// lookupAddress resolves a normalized address to its parcels within a single year.
+func (y *yearData) lookupAddress(norm string) []Property {
+	if y == nil {
+		return nil
+	}
+	var props []Property
+	for _, acct := range y.ByAddress[norm] {
+		if p, ok := y.ByAcct[acct]; ok {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// props returns the year's records keyed by account, or nil for a missing year.
+func (y *yearData) props() map[string]Property {
+	if y == nil {
+		return nil
+	}
+	return y.ByAcct
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverDatasets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"PropertyData_R_2025.txt",
+		"PropertyDataSupplemental_R_2025.txt",
+		"PropertyData_2023.txt",
+		"PropertyData_R_2024.txt",
+		"PropertyDataSupplemental_2022.txt", // no primary: skipped
+		"PropertyData_2025.csv",             // wrong extension
+		"PropertyData_R_25.txt",             // not a four-digit year
+		"notes.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("AccountNumber|SitusZip\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "PropertyData_2021.txt"), 0755); err != nil {
+		t.Fatal(err) // a directory, not an export
+	}
+
+	got, err := discoverDatasets(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []datasetFiles{
+		{Year: 2023, Primary: filepath.Join(dir, "PropertyData_2023.txt")},
+		{Year: 2024, Primary: filepath.Join(dir, "PropertyData_R_2024.txt")},
+		{Year: 2025, Primary: filepath.Join(dir, "PropertyData_R_2025.txt"), Supplemental: filepath.Join(dir, "PropertyDataSupplemental_R_2025.txt")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discoverDatasets =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if _, err := discoverDatasets(t.TempDir()); err == nil {
+		t.Error("no error for a directory without exports")
+	}
+}
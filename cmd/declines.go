@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Condition / quality decline ----------------
+
+// defaultDeprRise is the depreciation increase, in percentage points, reported as a
+// decline. Depreciation creeps up about a point a year on its own, so smaller rises are
+// ordinary aging.
+const defaultDeprRise = 5.0
+
+// declineResult is a parcel whose supplemental grades got worse between two years.
+type declineResult struct {
+	Property
+	Prior         Property
+	ConditionDrop int     // grade steps lost, 0 if unchanged or improved
+	QualityDrop   int     // grade steps lost, 0 if unchanged or improved
+	DeprRise      float64 // percentage points gained, 0 if below the threshold
+}
+
+// gradeDrop returns how many gradeRanks steps cur is below prior, or 0 if either grade is
+// unknown or the grade did not fall.
+func gradeDrop(prior, cur string) int {
+	p, ok1 := gradeRank(prior)
+	c, ok2 := gradeRank(cur)
+	if !ok1 || !ok2 || c >= p {
+		return 0
+	}
+	return p - c
+}
+
+// findDeclines returns parcels matching f in to whose condition or quality fell, or whose
+// depreciation rose by at least minDeprRise points, since from. The largest grade drops
+// come first, then the largest depreciation rises.
+func findDeclines(from, to *yearData, f parcelFilter, minDeprRise float64) []declineResult {
+	var results []declineResult
+	for _, p := range f.parcels(to) {
+		old, ok := from.ByAcct[p.AccountNum]
+		if !ok {
+			continue
+		}
+		r := declineResult{
+			Property:      p,
+			Prior:         old,
+			ConditionDrop: gradeDrop(old.Condition, p.Condition),
+			QualityDrop:   gradeDrop(old.Quality, p.Quality),
+		}
+		oldDepr, ok1 := parseDollar(old.DepreciationPercent)
+		curDepr, ok2 := parseDollar(p.DepreciationPercent)
+		if ok1 && ok2 && curDepr-oldDepr >= minDeprRise {
+			r.DeprRise = curDepr - oldDepr
+		}
+		if r.ConditionDrop > 0 || r.QualityDrop > 0 || r.DeprRise > 0 {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if da, db := a.ConditionDrop+a.QualityDrop, b.ConditionDrop+b.QualityDrop; da != db {
+			return da > db
+		}
+		if a.DeprRise != b.DeprRise {
+			return a.DeprRise > b.DeprRise
+		}
+		return a.SitusAddress < b.SitusAddress
+	})
+	return results
+}
+
+// describe summarizes what got worse, e.g. "Condition Average -> Fair, Depreciation +8 pts".
+func (r declineResult) describe() string {
+	var parts []string
+	if r.ConditionDrop > 0 {
+		parts = append(parts, fmt.Sprintf("Condition %s -> %s", r.Prior.Condition, r.Condition))
+	}
+	if r.QualityDrop > 0 {
+		parts = append(parts, fmt.Sprintf("Quality %s -> %s", r.Prior.Quality, r.Quality))
+	}
+	if r.DeprRise > 0 {
+		parts = append(parts, fmt.Sprintf("Depreciation %s%% -> %s%% (%+.0f pts)", r.Prior.DepreciationPercent, r.DepreciationPercent, r.DeprRise))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// showDeclines parses "declines [from=YYYY] [to=YYYY] [depr=<points>] [filters]" and lists
+// parcels whose condition, quality or depreciation got worse between the two years.
+func showDeclines(args string, hist *history) {
+	var years yearRange
+	minDepr := defaultDeprRise
+	f, err := parseFilterArgs(args, func(key, val string) (bool, error) {
+		if key != "depr" {
+			return years.option(key, val)
+		}
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil || v <= 0 {
+			return true, fmt.Errorf("depr=: invalid number of points %q", val)
+		}
+		minDepr = v
+		return true, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: declines [from=YYYY] [to=YYYY] [depr=<points>] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+	from, to, ok := years.resolve(hist)
+	if !ok {
+		return
+	}
+
+	start := time.Now()
+	results := findDeclines(from, to, f, minDepr)
+	fmt.Printf("\nFound %d declining properties %d -> %d in %s (depreciation rise ≥ %g pts) (%v)\n",
+		len(results), from.Year, to.Year, f, minDepr, time.Since(start).Truncate(time.Millisecond))
+
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		line := fmt.Sprintf("%-40s | %s", r.SitusAddress, r.describe())
+		lines = append(lines, line)
+		queries = append(queries, "acct="+r.AccountNum)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
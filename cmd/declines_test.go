@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDeclines(t *testing.T) {
+	from := &yearData{Year: 2024, ByAcct: map[string]Property{
+		"cond":    {AccountNum: "cond", SitusAddress: "1 A ST", Condition: "Good", Quality: "Average", DepreciationPercent: "20"},
+		"quality": {AccountNum: "quality", SitusAddress: "2 A ST", Condition: "Average", Quality: "Good", DepreciationPercent: "20"},
+		"depr":    {AccountNum: "depr", SitusAddress: "3 A ST", Condition: "Average", Quality: "Average", DepreciationPercent: "20"},
+		"aging":   {AccountNum: "aging", SitusAddress: "4 A ST", Condition: "Average", Quality: "Average", DepreciationPercent: "20"},
+		"better":  {AccountNum: "better", SitusAddress: "5 A ST", Condition: "Fair", Quality: "Average", DepreciationPercent: "30"},
+		"unknown": {AccountNum: "unknown", SitusAddress: "6 A ST", Condition: "", Quality: "Average"},
+		"gone":    {AccountNum: "gone", SitusAddress: "7 A ST", Condition: "Excellent"},
+	}}
+	to := &yearData{Year: 2025, ByAcct: map[string]Property{
+		"cond":    {AccountNum: "cond", SitusAddress: "1 A ST", Condition: "Poor", Quality: "Average", DepreciationPercent: "21"},
+		"quality": {AccountNum: "quality", SitusAddress: "2 A ST", Condition: "Average", Quality: "Fair", DepreciationPercent: "21"},
+		"depr":    {AccountNum: "depr", SitusAddress: "3 A ST", Condition: "Average", Quality: "Average", DepreciationPercent: "28"},
+		"aging":   {AccountNum: "aging", SitusAddress: "4 A ST", Condition: "Average", Quality: "Average", DepreciationPercent: "22"},
+		"better":  {AccountNum: "better", SitusAddress: "5 A ST", Condition: "Good", Quality: "Average", DepreciationPercent: "10"},
+		"unknown": {AccountNum: "unknown", SitusAddress: "6 A ST", Condition: "Poor", Quality: "Average"},
+		"new":     {AccountNum: "new", SitusAddress: "8 A ST", Condition: "Unsound"},
+	}}
+
+	var got []string
+	for _, r := range findDeclines(from, to, parcelFilter{}, 5) {
+		got = append(got, r.AccountNum)
+	}
+	// Good -> Poor is three steps, Good -> Fair two, then the 8-point depreciation rise.
+	if want := []string{"cond", "quality", "depr"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("findDeclines = %v, want %v", got, want)
+	}
+}
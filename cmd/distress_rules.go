@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ---------------- Distress scoring rules ----------------
+
+// distressRulesFile holds the tunable weights and thresholds for the distressed filter.
+// It is created with the defaults below the first time the filter runs and re-read on
+// every run, so edits take effect without restarting.
+var distressRulesFile = filepath.Join("data", "distress_rules.json")
+
+// distressRule configures one signal. A weight of 0 disables the signal.
+type distressRule struct {
+	Weight    float64 `json:"weight"`
+	Threshold float64 `json:"threshold"`
+	Note      string  `json:"note,omitempty"` // what Threshold means; informational only
+}
+
+// distressRules is the rule file: a parcel qualifies when the weights of the signals
+// it trips add up to at least MinScore.
+type distressRules struct {
+	MinScore float64                 `json:"min_score"`
+	Signals  map[string]distressRule `json:"signals"`
+}
+
+// distressInputs are the per-parcel measurements the signals are evaluated against.
+type distressInputs struct {
+	PriceRatio     float64 // parcel $/sqft ÷ neighborhood $/sqft
+	HasPriceRatio  bool
+	AgeGap         float64 // neighborhood mean year built − parcel year built
+	DeprGap        float64 // parcel depreciation − neighborhood mean
+	Depreciation   float64
+	Condition      string
+	ConditionDrop  int    // condition grade steps lost since the prior year
+	PriorCondition string // set when ConditionDrop > 0
+	Occupancy      occupancy
+	OwnerType      ownerType
+	HoldYears      float64 // years since the deed date; 0 when unknown
+	ValueChange    float64 // fractional change in total value from the prior year
+	HasValueChange bool
+	TaxProtest     bool
+}
+
+// distressSignal is one scoring rule known to the filter.
+type distressSignal struct {
+	Name        string
+	Description string // what Threshold means, written as the note in the default rule file
+	Default     distressRule
+	// Fires reports whether the signal trips for in at the given threshold and, if so,
+	// a short description of the measured value.
+	Fires func(in distressInputs, threshold float64) (bool, string)
+}
+
+// distressSignals lists every signal in display order.
+var distressSignals = []distressSignal{
+	{
+		Name:        "price_ratio",
+		Description: "$/sqft at or below threshold × neighborhood mean",
+		Default:     distressRule{Weight: 3, Threshold: 0.70},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.HasPriceRatio && in.PriceRatio <= t, fmt.Sprintf("%.0f%% of nbhd", in.PriceRatio*100)
+		},
+	},
+	{
+		Name:        "age_gap",
+		Description: "built at least threshold years before the neighborhood mean",
+		Default:     distressRule{Weight: 1, Threshold: 20},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.AgeGap >= t, fmt.Sprintf("%.0f yr older", in.AgeGap)
+		},
+	},
+	{
+		Name:        "depr_gap",
+		Description: "depreciation at least threshold points above the neighborhood mean",
+		Default:     distressRule{Weight: 1, Threshold: 15},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.DeprGap >= t, fmt.Sprintf("+%.0f pts", in.DeprGap)
+		},
+	},
+	{
+		Name:        "depreciation",
+		Description: "depreciation percent at or above threshold",
+		Default:     distressRule{Weight: 1, Threshold: 40},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.Depreciation >= t, fmt.Sprintf("%.0f%%", in.Depreciation)
+		},
+	},
+	{
+		Name:        "condition",
+		Description: "condition grade at or below threshold (0 Unsound, 1 Poor, 2 Fair, 3 Average)",
+		Default:     distressRule{Weight: 2, Threshold: 2},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			r, ok := gradeRank(in.Condition)
+			return ok && float64(r) <= t, in.Condition
+		},
+	},
+	{
+		Name:        "condition_drop",
+		Description: "condition fell at least threshold grade steps since the prior year",
+		Default:     distressRule{Weight: 2, Threshold: 1},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.ConditionDrop > 0 && float64(in.ConditionDrop) >= t, in.PriorCondition + " -> " + in.Condition
+		},
+	},
+	{
+		Name:        "absentee",
+		Description: "owner mailing address is not the situs address (threshold unused)",
+		Default:     distressRule{Weight: 1},
+		Fires: func(in distressInputs, _ float64) (bool, string) {
+			return in.Occupancy.absentee(), in.Occupancy.String()
+		},
+	},
+	{
+		Name:        "estate_owner",
+		Description: "owner is an estate or heirs (threshold unused)",
+		Default:     distressRule{Weight: 2},
+		Fires: func(in distressInputs, _ float64) (bool, string) {
+			return in.OwnerType == ownerEstate, ""
+		},
+	},
+	{
+		Name:        "bank_owned",
+		Description: "owner is a bank or mortgage company, likely REO (threshold unused)",
+		Default:     distressRule{Weight: 2},
+		Fires: func(in distressInputs, _ float64) (bool, string) {
+			return in.OwnerType == ownerBank, ""
+		},
+	},
+	{
+		Name:        "long_hold",
+		Description: "deed held at least threshold years",
+		Default:     distressRule{Weight: 1, Threshold: 10},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.HoldYears >= t, fmt.Sprintf("%.0f yr", in.HoldYears)
+		},
+	},
+	{
+		Name:        "tax_shock",
+		Description: "total value up more than threshold (fraction) from the prior year",
+		Default:     distressRule{Weight: 1, Threshold: 0.15},
+		Fires: func(in distressInputs, t float64) (bool, string) {
+			return in.HasValueChange && in.ValueChange > t, fmt.Sprintf("%+.0f%%", in.ValueChange*100)
+		},
+	},
+	{
+		Name:        "tax_protest",
+		Description: "ARB protest filed (threshold unused)",
+		Default:     distressRule{Weight: 1},
+		Fires: func(in distressInputs, _ float64) (bool, string) {
+			return in.TaxProtest, ""
+		},
+	},
+}
+
+// signalHit records a tripped signal and the points it contributed.
+type signalHit struct {
+	Name   string
+	Weight float64
+	Detail string
+}
+
+func defaultDistressRules() distressRules {
+	r := distressRules{MinScore: 4, Signals: make(map[string]distressRule)}
+	for _, s := range distressSignals {
+		rule := s.Default
+		rule.Note = s.Description
+		r.Signals[s.Name] = rule
+	}
+	return r
+}
+
+// loadDistressRules reads distressRulesFile, falling back to the defaults for any signal
+// it does not mention. Unknown signal names are reported so typos do not go unnoticed.
+func loadDistressRules() (distressRules, error) {
+	rules := defaultDistressRules()
+	if err := loadJSONConfig(distressRulesFile, &rules); err != nil {
+		return rules, err
+	}
+	known := make(map[string]bool, len(distressSignals))
+	for _, s := range distressSignals {
+		known[s.Name] = true
+	}
+	for name := range rules.Signals {
+		if !known[name] {
+			fmt.Fprintf(os.Stderr, "warning: %s: unknown signal %q ignored\n", distressRulesFile, name)
+		}
+	}
+	return rules, nil
+}
+
+// UnmarshalJSON merges the file over the rules already in r field by field, so an entry
+// that sets only a signal's weight keeps its default threshold.
+func (r *distressRules) UnmarshalJSON(b []byte) error {
+	var file struct {
+		MinScore *float64 `json:"min_score"`
+		Signals  map[string]struct {
+			Weight    *float64 `json:"weight"`
+			Threshold *float64 `json:"threshold"`
+			Note      *string  `json:"note"`
+		} `json:"signals"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return err
+	}
+	if file.MinScore != nil {
+		r.MinScore = *file.MinScore
+	}
+	if r.Signals == nil {
+		r.Signals = make(map[string]distressRule, len(file.Signals))
+	}
+	for name, f := range file.Signals {
+		rule := r.Signals[name]
+		if f.Weight != nil {
+			rule.Weight = *f.Weight
+		}
+		if f.Threshold != nil {
+			rule.Threshold = *f.Threshold
+		}
+		if f.Note != nil {
+			rule.Note = *f.Note
+		}
+		r.Signals[name] = rule
+	}
+	return nil
+}
+
+// score evaluates every signal against in and returns the total and the tripped signals,
+// highest weight first.
+func (r distressRules) score(in distressInputs) (float64, []signalHit) {
+	var total float64
+	var hits []signalHit
+	for _, s := range distressSignals {
+		rule := r.Signals[s.Name]
+		if rule.Weight == 0 {
+			continue
+		}
+		if fired, detail := s.Fires(in, rule.Threshold); fired {
+			total += rule.Weight
+			hits = append(hits, signalHit{Name: s.Name, Weight: rule.Weight, Detail: detail})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Weight > hits[j].Weight })
+	return total, hits
+}
+
+// formatBreakdown renders hits as "price_ratio+3 (62% of nbhd), absentee+1".
+func formatBreakdown(hits []signalHit) string {
+	parts := make([]string, len(hits))
+	for i, h := range hits {
+		parts[i] = fmt.Sprintf("%s+%g", h.Name, h.Weight)
+		if h.Detail != "" {
+			parts[i] += " (" + h.Detail + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
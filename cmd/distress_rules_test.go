@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDistressRulesPartialEntry(t *testing.T) {
+	saved := distressRulesFile
+	defer func() { distressRulesFile = saved }()
+	distressRulesFile = filepath.Join(t.TempDir(), "distress_rules.json")
+	file := `{"signals": {"price_ratio": {"weight": 5}, "long_hold": {"threshold": 20}}}`
+	if err := os.WriteFile(distressRulesFile, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := loadDistressRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := defaultDistressRules()
+	if rules.MinScore != def.MinScore {
+		t.Errorf("MinScore = %g, want default %g", rules.MinScore, def.MinScore)
+	}
+	if got := rules.Signals["price_ratio"]; got.Weight != 5 || got.Threshold != def.Signals["price_ratio"].Threshold {
+		t.Errorf("price_ratio = %+v, want weight 5 and the default threshold %g", got, def.Signals["price_ratio"].Threshold)
+	}
+	if got := rules.Signals["long_hold"]; got.Threshold != 20 || got.Weight != def.Signals["long_hold"].Weight {
+		t.Errorf("long_hold = %+v, want threshold 20 and the default weight %g", got, def.Signals["long_hold"].Weight)
+	}
+	if got, want := rules.Signals["age_gap"], def.Signals["age_gap"]; got != want {
+		t.Errorf("age_gap = %+v, want the default %+v", got, want)
+	}
+}
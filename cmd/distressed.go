@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,77 +17,108 @@ type distressedResult struct {
 	PriceRatio float64
 	AgeGap     float64
 	DeprGap    float64
+	Score      float64
+	Signals    []signalHit
 	Flags      string
 	NbhdCount  int
 }
 
+// minNbhdComps is the fewest parcels a benchmark may be built from.
+const minNbhdComps = 10
+
+// nbhdBenchmark holds the average $/sqft, year built and depreciation of a neighborhood.
+type nbhdBenchmark struct {
+	priceSqft float64
+	yearBuilt float64
+	depr      float64
+	count     int
+}
+
 // handleSubdivisionQuery prompts the user to choose an analysis method and displays results.
-func handleSubdivisionQuery(sub string, props2025 map[string]Property, props2024 map[string]Property) {
+// f names the subdivision and may narrow it further (owner type, mailing class, ...).
+func handleSubdivisionQuery(f parcelFilter, hist *history) {
+	cur := hist.current()
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Printf("\nSelect analysis for subdivision %s:\n  1) Relative Improvement (price per sqft vs nearby)\n  2) Distressed-Property Filter\n  3) List \"Poor\" Condition Properties\nChoice (1/2/3, default 1): ", sub)
+		fmt.Printf("\nSelect analysis for %s:\n  1) Relative Improvement (price per sqft or value vs nearby)\n  2) Distressed-Property Filter\n  3) List \"Poor\" or Worse Condition Properties\n  4) Vacant Lots & Teardown Candidates\nChoice (1/2/3/4, default 1): ", f)
 		choice, _ := reader.ReadString('\n')
 		choice = strings.TrimSpace(choice)
 		if choice == "" || choice == "1" {
+			opts := promptUndervaluedOptions(reader)
 			startSub := time.Now()
-			results := findUndervaluedInSubdivision(sub, props2025)
-			fmt.Printf("\nFound %d undervalued properties in subdivision %s (%v)\n", len(results), sub, time.Since(startSub).Truncate(time.Millisecond))
+			var candidates []Property
+			for _, p := range f.parcels(cur) {
+				if p.Latitude != "" && p.Longitude != "" {
+					candidates = append(candidates, p)
+				}
+			}
+			results := undervaluedFromCandidates(candidates, cur.ByAcct, cur.spatial, opts)
+			fmt.Printf("\nFound %d undervalued properties in %s by %s (≤ -%.1fσ within %.2f mi, n ≥ %d) (%v)\n",
+				len(results), f, opts.Metric, opts.Sigma, opts.Radius, opts.MinComps, time.Since(startSub).Truncate(time.Millisecond))
 			var lines []string
-			var addrs []string
+			var queries []string
 			for _, r := range results {
-				val, _ := parseDollar(r.ImprovementValue)
-				line := fmt.Sprintf("%-40s | Imp: %9.0f | μ=%.0f σ=%.0f n=%d", r.SitusAddress, val, r.Mean, r.StdDev, r.NeighborCount)
+				line := fmt.Sprintf("%-40s | Value: %9.2f | μ=%.2f σ=%.2f n=%d | z=%5.2f", r.SitusAddress, r.Value, r.Mean, r.StdDev, r.NeighborCount, r.ZScore)
 				lines = append(lines, line)
-				addrs = append(addrs, r.SitusAddress)
+				queries = append(queries, "acct="+r.AccountNum)
 				fmt.Println(line)
 			}
 			fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
-			interactiveSelect(addrs, lines, props2025, props2024, true)
+			interactiveSelect(queries, lines, hist, true)
 			return
 		}
 		if choice == "2" {
-			startSub := time.Now()
-			results := findDistressedInSubdivision(sub, props2025, props2024)
-			fmt.Printf("\nFound %d distressed properties in subdivision %s (%v)\n", len(results), sub, time.Since(startSub).Truncate(time.Millisecond))
-			// Display and enable interactive selection.
-			var lines []string
-			var addrs []string
-			for _, r := range results {
-				priceSq, _ := parseDollar(r.TotalValue)
-				living, _ := parseDollar(r.LivingArea)
-				line := fmt.Sprintf("%-40s | $/sqft: %6.0f (%.0f%% of nbhd) | AgeGap: %2.0f | DeprGap: %3.0f | Flags: %s",
-					r.SitusAddress, priceSq/living, r.PriceRatio*100, r.AgeGap, r.DeprGap, r.Flags)
-				lines = append(lines, line)
-				addrs = append(addrs, r.SitusAddress)
-				fmt.Println(line)
+			rules, err := loadDistressRules()
+			if err != nil {
+				fmt.Printf("Failed to load distress rules: %v\n", err)
+				return
 			}
-			fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
-			interactiveSelect(addrs, lines, props2025, props2024, true)
+			startSub := time.Now()
+			results := findDistressed(f, cur, hist.prior(cur.Year).props(), rules)
+			fmt.Printf("\nFound %d distressed properties in %s scoring ≥ %g (%v)\n", len(results), f, rules.MinScore, time.Since(startSub).Truncate(time.Millisecond))
+			showDistressedResults(results, false, hist)
 			return
 		}
 		if choice == "3" {
 			startSub := time.Now()
-			results := findPoorConditionInSubdivision(sub, props2025)
-			fmt.Printf("\nFound %d 'Poor' condition properties in subdivision %s (%v)\n", len(results), sub, time.Since(startSub).Truncate(time.Millisecond))
+			var results []Property
+			for _, p := range findPoorConditionInSubdivision(f.Subdivision, cur.ByAcct) {
+				if f.matches(p) {
+					results = append(results, p)
+				}
+			}
+			fmt.Printf("\nFound %d 'Poor' or worse condition properties in %s (%v)\n", len(results), f, time.Since(startSub).Truncate(time.Millisecond))
 			var lines []string
-			var addrs []string
+			var queries []string
 			for _, p := range results {
 				line := fmt.Sprintf("%-40s | Condition: %s", p.SitusAddress, p.Condition)
 				lines = append(lines, line)
-				addrs = append(addrs, p.SitusAddress)
+				queries = append(queries, "acct="+p.AccountNum)
 				fmt.Println(line)
 			}
 			fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
-			interactiveSelect(addrs, lines, props2025, props2024, true)
+			interactiveSelect(queries, lines, hist, true)
 			return
 		}
-		fmt.Println("Invalid choice – enter 1, 2, or 3.")
+		if choice == "4" {
+			s := defaultInfillSettings()
+			startSub := time.Now()
+			results := findInfill(cur, f, s)
+			fmt.Printf("\nFound %d %s in %s (%v)\n", len(results), s.label(), f, time.Since(startSub).Truncate(time.Millisecond))
+			showInfillResults(results, hist)
+			return
+		}
+		fmt.Println("Invalid choice – enter 1, 2, 3 or 4.")
 	}
 }
 
-// findDistressedInSubdivision implements the SQL-like distressed-property filter for a single subdivision.
-func findDistressedInSubdivision(sub string, props2025 map[string]Property, props2024 map[string]Property) []distressedResult {
-	sub = strings.ToUpper(strings.TrimSpace(sub))
+// findDistressed scores every parcel matching f against the distress rules and returns
+// those reaching rules.MinScore, highest score first. Each parcel is benchmarked against
+// its own subdivision, so results from different neighborhoods can be ranked in one list;
+// parcels in subdivisions with fewer than minNbhdComps are skipped. y is the year being
+// screened and prevProps the year before it (nil if none), keyed by account.
+func findDistressed(f parcelFilter, y *yearData, prevProps map[string]Property, rules distressRules) []distressedResult {
+	props := y.ByAcct
 
 	// 1. Build neighborhood benchmarks
 	type agg struct {
@@ -97,7 +129,7 @@ func findDistressedInSubdivision(sub string, props2025 map[string]Property, prop
 	}
 	aggs := make(map[string]*agg)
 
-	for _, p := range props2025 {
+	for _, p := range props {
 		nb := strings.ToUpper(strings.TrimSpace(p.Subdivision))
 		if nb == "" {
 			continue
@@ -121,18 +153,12 @@ func findDistressedInSubdivision(sub string, props2025 map[string]Property, prop
 		a.count++
 	}
 
-	type stats struct {
-		priceSqft float64
-		yearBuilt float64
-		depr      float64
-		count     int
-	}
-	nbhdStats := make(map[string]stats, len(aggs))
+	nbhdStats := make(map[string]nbhdBenchmark, len(aggs))
 	for nb, a := range aggs {
 		if a.count == 0 {
 			continue
 		}
-		nbhdStats[nb] = stats{
+		nbhdStats[nb] = nbhdBenchmark{
 			priceSqft: a.sumPriceSqft / float64(a.count),
 			yearBuilt: a.sumYearBuilt / float64(a.count),
 			depr:      a.sumDepr / float64(a.count),
@@ -140,95 +166,128 @@ func findDistressedInSubdivision(sub string, props2025 map[string]Property, prop
 		}
 	}
 
-	// 2. Evaluate each parcel in the subdivision
+	// 2. Evaluate each parcel matching the filter
 	var results []distressedResult
 	now := time.Now()
 
-	for _, p := range props2025 {
+	for _, p := range f.parcels(y) {
 		nb := strings.ToUpper(strings.TrimSpace(p.Subdivision))
-		if nb != sub {
-			continue
-		}
 		stat, ok := nbhdStats[nb]
-		if !ok || stat.count < 10 {
+		if !ok || stat.count < minNbhdComps {
 			continue // unreliable comps
 		}
 
+		in := distressInputs{Condition: p.Condition, OwnerType: classifyOwner(p.OwnerName)}
 		total, ok1 := parseDollar(p.TotalValue)
 		living, ok2 := parseDollar(p.LivingArea)
-		if !ok1 || !ok2 || living == 0 {
-			continue
-		}
-		priceRatio := (total / living) / stat.priceSqft
-		if priceRatio > 0.70 {
-			continue // needs to be >=30% cheaper
+		if ok1 && ok2 && living > 0 && stat.priceSqft > 0 {
+			in.PriceRatio = (total / living) / stat.priceSqft
+			in.HasPriceRatio = true
 		}
 
 		// Age & depreciation gaps
-		yearBuilt, errY := strconv.Atoi(strings.TrimSpace(p.YearBuilt))
-		ageGap := 0.0
-		if errY == nil {
-			ageGap = stat.yearBuilt - float64(yearBuilt)
-		}
-		deprVal, _ := parseDollar(p.DepreciationPercent)
-		deprGap := deprVal - stat.depr
-
-		physFlag := strings.EqualFold(p.Condition, "Poor") || strings.EqualFold(p.Condition, "Fair") || deprVal >= 40
-		if !(ageGap >= 20 || deprGap >= 15 || physFlag) {
-			continue
+		if yearBuilt, err := strconv.Atoi(strings.TrimSpace(p.YearBuilt)); err == nil {
+			in.AgeGap = stat.yearBuilt - float64(yearBuilt)
 		}
+		in.Depreciation, _ = parseDollar(p.DepreciationPercent)
+		in.DeprGap = in.Depreciation - stat.depr
 
 		// Ownership / finance distress signals
-		flagAbsentee := 0
-		if p.City != "" && !strings.Contains(strings.ToUpper(p.OwnerCityState), strings.ToUpper(p.City)) {
-			flagAbsentee = 1
-		}
-		flagLongHold := 0
+		in.Occupancy = classifyOccupancy(p)
 		if t, err := time.Parse("01-02-2006", p.DeedDate); err == nil {
-			if now.Sub(t) >= 10*365*24*time.Hour {
-				flagLongHold = 1
-			}
-		}
-		flagTaxProtest := 0
-		if strings.EqualFold(strings.TrimSpace(p.ARBIndicator), "Y") {
-			flagTaxProtest = 1
+			in.HoldYears = now.Sub(t).Hours() / (24 * 365)
 		}
-		flagTaxShock := 0
-		if prev, ok := props2024[normalize(p.SitusAddress)]; ok {
-			if prevVal, ok := parseDollar(prev.TotalValue); ok && prevVal > 0 && total > 1.15*prevVal {
-				flagTaxShock = 1
+		in.TaxProtest = strings.EqualFold(strings.TrimSpace(p.ARBIndicator), "Y")
+		if prev, ok := prevProps[p.AccountNum]; ok {
+			if prevVal, ok := parseDollar(prev.TotalValue); ok && prevVal > 0 && ok1 {
+				in.ValueChange = total/prevVal - 1
+				in.HasValueChange = true
+			}
+			in.ConditionDrop = gradeDrop(prev.Condition, p.Condition)
+			if in.ConditionDrop > 0 {
+				in.PriorCondition = prev.Condition
 			}
-		}
-		if flagAbsentee+flagLongHold+flagTaxProtest+flagTaxShock == 0 {
-			continue
 		}
 
-		flagList := []string{}
-		if flagAbsentee == 1 {
-			flagList = append(flagList, "absentee")
-		}
-		if flagLongHold == 1 {
-			flagList = append(flagList, "longHold")
-		}
-		if flagTaxShock == 1 {
-			flagList = append(flagList, "taxShock")
-		}
-		if flagTaxProtest == 1 {
-			flagList = append(flagList, "taxProtest")
+		score, hits := rules.score(in)
+		if len(hits) == 0 || score < rules.MinScore {
+			continue
 		}
-		if physFlag {
-			flagList = append(flagList, "physical")
+		names := make([]string, len(hits))
+		for i, h := range hits {
+			names[i] = h.Name
 		}
 
 		results = append(results, distressedResult{
 			Property:   p,
-			PriceRatio: priceRatio,
-			AgeGap:     ageGap,
-			DeprGap:    deprGap,
-			Flags:      strings.Join(flagList, ","),
+			PriceRatio: in.PriceRatio,
+			AgeGap:     in.AgeGap,
+			DeprGap:    in.DeprGap,
+			Score:      score,
+			Signals:    hits,
+			Flags:      strings.Join(names, ","),
 			NbhdCount:  stat.count,
 		})
 	}
 
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].SitusAddress < results[j].SitusAddress
+		}
+		return results[i].Score > results[j].Score
+	})
 	return results
 }
+
+// handleDistressedCommand runs the distressed filter over the geography described by args
+// (see parseParcelFilter) and shows one ranked list.
+func handleDistressedCommand(args string, hist *history) {
+	f, err := parseParcelFilter(args)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: distressed [sub=<Subdivision>] [zip=<Zip>] [city=<City>] [isd=<School District>] [near=<lat>,<lon>,<miles>] [owntype=<llc,trust,estate,bank,...>] [occ=<occupied,local,instate,outofstate,pobox>] [zone=] [allows=] [units=] | county")
+		return
+	}
+	rules, err := loadDistressRules()
+	if err != nil {
+		fmt.Printf("Failed to load distress rules: %v\n", err)
+		return
+	}
+	cur := hist.current()
+	start := time.Now()
+	results := findDistressed(f, cur, hist.prior(cur.Year).props(), rules)
+	fmt.Printf("\nFound %d distressed properties in %s scoring ≥ %g (%v)\n", len(results), f, rules.MinScore, time.Since(start).Truncate(time.Millisecond))
+	showDistressedResults(results, true, hist)
+}
+
+// showDistressedResults prints the ranked list and opens the selection list. The
+// subdivision column is added when results may span several neighborhoods.
+func showDistressedResults(results []distressedResult, showSub bool, hist *history) {
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		line := fmt.Sprintf("%-40s | Score: %4.1f | %s", r.SitusAddress, r.Score, formatBreakdown(r.Signals))
+		if showSub {
+			line = fmt.Sprintf("%-40s | %-25.25s | Score: %4.1f | %s", r.SitusAddress, r.Subdivision, r.Score, formatBreakdown(r.Signals))
+		}
+		lines = append(lines, line)
+		queries = append(queries, "acct="+r.AccountNum)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
+
+// promptUndervaluedOptions asks for the comparison metric, radius, minimum comp count and
+// sigma cutoff, keeping the defaults for any blank answer.
+func promptUndervaluedOptions(reader *bufio.Reader) undervaluedOptions {
+	opts := defaultUndervaluedOptions()
+	fmt.Printf("Metric: 1) %s  2) %s  3) %s  4) %s\n", metricImpSqft, metricTotalSqft, metricLandSqft, metricRawImp)
+	if m := compMetric(promptInt(reader, "Metric", int(opts.Metric))); m >= metricImpSqft && m <= metricRawImp {
+		opts.Metric = m
+	}
+	opts.Radius = promptFloat(reader, "Radius in miles", opts.Radius)
+	opts.MinComps = promptInt(reader, "Minimum comps", opts.MinComps)
+	opts.Sigma = promptFloat(reader, "Std deviations below mean", opts.Sigma)
+	return opts
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ---------------- Parcel filters ----------------
+
+// parcelFilter restricts a search to a geography. The zero value matches the whole county.
+type parcelFilter struct {
+	Subdivision string
+	Zip         string
+	City        string
+	School      string
+	OwnerTypes  []ownerType // any of
+	Occupancy   []occupancy // any of
+	Zones       []string    // base zoning district codes, any of
+	Allows      []string    // residential uses the zoning must permit on this lot, all of
+	MinUnits    int         // dwelling units the zoning would allow on this lot
+	Near        *radiusFilter
+}
+
+// radiusFilter matches parcels within Miles of a point.
+type radiusFilter struct {
+	Lat, Lon, Miles float64
+}
+
+// filterKeyPattern finds the start of each key=value pair. Values run until the next
+// key, so multi-word values such as city=FORT WORTH need no quoting.
+var filterKeyPattern = regexp.MustCompile(`(?i)(?:^|\s)([a-z_]+)=`)
+
+// parseKeyValues splits "zip=76107 city=FORT WORTH" into lower-cased keys and trimmed
+// values. Text before the first key is returned as rest.
+func parseKeyValues(s string) (kv map[string]string, rest string) {
+	kv = make(map[string]string)
+	locs := filterKeyPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return kv, strings.TrimSpace(s)
+	}
+	rest = strings.TrimSpace(s[:locs[0][0]])
+	for i, loc := range locs {
+		end := len(s)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		key := strings.ToLower(s[loc[2]:loc[3]])
+		kv[key] = strings.TrimSpace(s[loc[1]:end])
+	}
+	return kv, rest
+}
+
+// parseParcelFilter builds a filter from key=value arguments:
+//
+//	sub=<Subdivision> zip=<Zip> city=<City> isd=<School District> near=<lat>,<lon>,<miles>
+//	owntype=<llc,trust,...> occ=<occupied,local,instate,outofstate,pobox>
+//	zone=<A-5,B,...> allows=<sf,duplex,mf,adu> units=<N>
+//
+// "county" (or no arguments) selects the whole county.
+func parseParcelFilter(args string) (parcelFilter, error) {
+	return parseFilterArgs(args, nil)
+}
+
+// parseFilterArgs is parseParcelFilter for commands with options of their own. Each
+// key=value pair is offered to option first; keys it does not handle are filters.
+func parseFilterArgs(args string, option func(key, val string) (bool, error)) (parcelFilter, error) {
+	var f parcelFilter
+	kv, rest := parseKeyValues(args)
+	if rest != "" && !strings.EqualFold(rest, "county") {
+		return f, fmt.Errorf("unexpected %q; use key=value filters or 'county'", rest)
+	}
+	for key, val := range kv {
+		if option != nil {
+			handled, err := option(key, val)
+			if err != nil {
+				return f, err
+			}
+			if handled {
+				continue
+			}
+		}
+		if err := f.set(key, val); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// set applies one key=value pair to the filter.
+func (f *parcelFilter) set(key, val string) error {
+	switch key {
+	case "sub":
+		f.Subdivision = val
+	case "zip":
+		f.Zip = val
+	case "city":
+		f.City = val
+	case "isd", "school":
+		f.School = val
+	case "owntype":
+		types, err := parseOwnerTypes(val)
+		if err != nil {
+			return err
+		}
+		f.OwnerTypes = types
+	case "occ":
+		classes, err := parseOccupancies(val)
+		if err != nil {
+			return err
+		}
+		f.Occupancy = classes
+	case "zone":
+		f.Zones = nil
+		for _, z := range strings.Split(val, ",") {
+			if z = strings.ToUpper(strings.TrimSpace(z)); z != "" {
+				f.Zones = append(f.Zones, z)
+			}
+		}
+	case "allows":
+		uses, err := parseResidentialUses(val)
+		if err != nil {
+			return err
+		}
+		f.Allows = uses
+	case "units":
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return fmt.Errorf("units= expects a positive whole number, got %q", val)
+		}
+		f.MinUnits = n
+	case "near":
+		parts := strings.Split(val, ",")
+		if len(parts) != 3 {
+			return fmt.Errorf("near= expects <lat>,<lon>,<miles>, got %q", val)
+		}
+		var nums [3]float64
+		for i, p := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+			if err != nil {
+				return fmt.Errorf("near=: invalid number %q", p)
+			}
+			nums[i] = v
+		}
+		f.Near = &radiusFilter{Lat: nums[0], Lon: nums[1], Miles: nums[2]}
+	default:
+		return fmt.Errorf("unknown filter %q", key)
+	}
+	return nil
+}
+
+// String describes the filter for result headers.
+func (f parcelFilter) String() string {
+	var parts []string
+	add := func(label, val string) {
+		if val != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", label, val))
+		}
+	}
+	add("subdivision", f.Subdivision)
+	add("zip", f.Zip)
+	add("city", f.City)
+	add("school district", f.School)
+	if len(f.OwnerTypes) > 0 {
+		names := make([]string, len(f.OwnerTypes))
+		for i, t := range f.OwnerTypes {
+			names[i] = t.String()
+		}
+		add("owner type", strings.Join(names, "/"))
+	}
+	if len(f.Occupancy) > 0 {
+		names := make([]string, len(f.Occupancy))
+		for i, o := range f.Occupancy {
+			names[i] = o.String()
+		}
+		add("mailing", strings.Join(names, "/"))
+	}
+	add("zoning", strings.Join(f.Zones, "/"))
+	if len(f.Allows) > 0 {
+		add("zoning allows", strings.Join(f.Allows, "+"))
+	}
+	if f.MinUnits > 0 {
+		parts = append(parts, fmt.Sprintf("zoning allows ≥ %d units", f.MinUnits))
+	}
+	if f.Near != nil {
+		parts = append(parts, fmt.Sprintf("within %.2f mi of (%.6f, %.6f)", f.Near.Miles, f.Near.Lat, f.Near.Lon))
+	}
+	if len(parts) == 0 {
+		return "the whole county"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matches reports whether p satisfies every non-radius criterion. Radius matching is
+// done by parcels, which can use the spatial index.
+func (f parcelFilter) matches(p Property) bool {
+	fold := func(a, b string) bool { return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) }
+	if f.Subdivision != "" && !fold(p.Subdivision, f.Subdivision) {
+		return false
+	}
+	if f.Zip != "" && !strings.HasPrefix(strings.TrimSpace(p.SitusZip), strings.TrimSpace(f.Zip)) {
+		return false
+	}
+	if f.City != "" && !fold(p.City, f.City) {
+		return false
+	}
+	if f.School != "" && !fold(p.SchoolDistrict, f.School) {
+		return false
+	}
+	if len(f.OwnerTypes) > 0 && !slices.Contains(f.OwnerTypes, classifyOwner(p.OwnerName)) {
+		return false
+	}
+	if len(f.Occupancy) > 0 && !slices.Contains(f.Occupancy, classifyOccupancy(p)) {
+		return false
+	}
+	if len(f.Zones) > 0 && !f.matchesZone(p.zoning) {
+		return false
+	}
+	if (len(f.Allows) > 0 || f.MinUnits > 0) && !f.matchesUses(p) {
+		return false
+	}
+	return true
+}
+
+// parcels returns every parcel in y that satisfies the filter. A radius filter is
+// answered from the spatial index rather than by scanning the county.
+func (f parcelFilter) parcels(y *yearData) []Property {
+	y.ensureZoning()
+	var out []Property
+	if f.Near != nil {
+		for _, hit := range y.spatial.within(f.Near.Lat, f.Near.Lon, f.Near.Miles) {
+			if p := y.ByAcct[hit.Acct]; f.matches(p) {
+				out = append(out, p)
+			}
+		}
+		return out
+	}
+	for _, p := range y.ByAcct {
+		if f.matches(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// matchesZone reports whether any base zone of the parcel is one of f.Zones, either as
+// written in the shapefile or as the dictionary district it resolves to.
+func (f parcelFilter) matchesZone(z parcelZoning) bool {
+	for _, code := range z.Base {
+		key, _, _ := lookupZoningDistrict(code)
+		for _, want := range f.Zones {
+			if strings.EqualFold(code, want) || key == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesUses reports whether some base district of the parcel permits every use in
+// f.Allows on a lot of this size and would allow at least f.MinUnits units.
+func (f parcelFilter) matchesUses(p Property) bool {
+	lot, _ := parseDollar(p.LandSqFt)
+	for _, d := range p.zoning.baseDistricts() {
+		if lot < d.MinLotSqFt {
+			continue
+		}
+		ok := true
+		for _, use := range f.Allows {
+			ok = ok && d.permits(use)
+		}
+		if ok && d.maxUnits(lot) >= f.MinUnits {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Vacant lots & teardowns ----------------
+
+// infillSettings are the thresholds for the infill command. Zero lot bounds mean no bound.
+type infillSettings struct {
+	Vacant, Teardown bool
+	MaxImprovement   float64 // improvement value at or below which a lot counts as vacant
+	MinLandShare     float64 // percent of total value in the land for a teardown
+	MinAge           int     // structure age, in years, that makes a teardown on its own
+	MinDepr          float64 // depreciation percent that makes a teardown on its own
+	MinLot, MaxLot   float64 // lot size range in square feet
+}
+
+// defaultInfillSettings finds both kinds: lots with under $5,000 of improvements, and
+// houses at least 60 years old, in Fair or worse condition or at least 50% depreciated
+// whose land is at least 60% of the total value.
+func defaultInfillSettings() infillSettings {
+	return infillSettings{
+		Vacant:         true,
+		Teardown:       true,
+		MaxImprovement: 5000,
+		MinLandShare:   60,
+		MinAge:         60,
+		MinDepr:        50,
+	}
+}
+
+// option applies one infill command option, reporting whether key was one of them.
+func (s *infillSettings) option(key, val string) (bool, error) {
+	var err error
+	switch key {
+	case "kind":
+		switch strings.ToLower(val) {
+		case "vacant":
+			s.Vacant, s.Teardown = true, false
+		case "teardown":
+			s.Vacant, s.Teardown = false, true
+		case "both", "all":
+			s.Vacant, s.Teardown = true, true
+		default:
+			err = fmt.Errorf("kind=: unknown kind %q (use vacant, teardown or both)", val)
+		}
+	case "lot":
+		s.MinLot, s.MaxLot, err = parseRange(key, val)
+	case "share":
+		s.MinLandShare, err = strconv.ParseFloat(val, 64)
+		if err != nil || s.MinLandShare < 0 || s.MinLandShare > 100 {
+			err = fmt.Errorf("share=: invalid percentage %q", val)
+		}
+	case "age":
+		s.MinAge, err = strconv.Atoi(val)
+		if err != nil || s.MinAge < 0 {
+			err = fmt.Errorf("age=: invalid number of years %q", val)
+		}
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// label names what the settings search for, for result headers.
+func (s infillSettings) label() string {
+	switch {
+	case s.Vacant && s.Teardown:
+		return "vacant lots and teardown candidates"
+	case s.Vacant:
+		return "vacant lots"
+	default:
+		return "teardown candidates"
+	}
+}
+
+// infillResult is a vacant lot or a teardown candidate.
+type infillResult struct {
+	Property
+	Kind      string // "Vacant" or "Teardown"
+	Land      float64
+	LandShare float64 // percent of total value
+	LotSqFt   float64
+	Reasons   []string // what makes the structure a teardown
+}
+
+// teardownReasons lists what marks the structure on p as past its useful life, measured
+// against the appraisal year.
+func (s infillSettings) teardownReasons(p Property, year int) []string {
+	var reasons []string
+	if built, err := strconv.Atoi(strings.TrimSpace(p.YearBuilt)); err == nil && built > 0 && year-built >= s.MinAge {
+		reasons = append(reasons, fmt.Sprintf("built %d (%d yrs)", built, year-built))
+	}
+	if rank, ok := gradeRank(p.Condition); ok && rank <= gradeRanks["FAIR"] {
+		reasons = append(reasons, "condition "+p.Condition)
+	}
+	if depr, ok := parseNumber(p.DepreciationPercent); ok && depr >= s.MinDepr {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% depreciated", depr))
+	}
+	return reasons
+}
+
+// findInfill returns parcels matching f that are vacant or teardown candidates under s,
+// highest land share first, then by land value.
+func findInfill(y *yearData, f parcelFilter, s infillSettings) []infillResult {
+	var results []infillResult
+	for _, p := range f.parcels(y) {
+		land, ok := parseDollar(p.LandValue)
+		if !ok || land <= 0 {
+			continue
+		}
+		// A lot of unknown size cannot be shown to fit a lot= range.
+		lot, ok := lotSqFt(p)
+		if (s.MinLot > 0 || s.MaxLot > 0) && (!ok || !inRange(lot, s.MinLot, s.MaxLot)) {
+			continue
+		}
+		// A blank improvement value is unknown, not a vacant lot.
+		impr, ok := parseDollar(p.ImprovementValue)
+		if !ok {
+			continue
+		}
+		total, ok := parseDollar(p.TotalValue)
+		if !ok || total <= 0 {
+			total = land + impr
+		}
+		r := infillResult{Property: p, Land: land, LandShare: 100 * land / total, LotSqFt: lot}
+		if impr <= s.MaxImprovement {
+			if !s.Vacant {
+				continue
+			}
+			r.Kind = "Vacant"
+		} else {
+			if !s.Teardown || r.LandShare < s.MinLandShare {
+				continue
+			}
+			if r.Reasons = s.teardownReasons(p, y.Year); len(r.Reasons) == 0 {
+				continue
+			}
+			r.Kind = "Teardown"
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.LandShare != b.LandShare {
+			return a.LandShare > b.LandShare
+		}
+		if a.Land != b.Land {
+			return a.Land > b.Land
+		}
+		return a.SitusAddress < b.SitusAddress
+	})
+	return results
+}
+
+// showInfillResults opens the paged selection list over the results.
+func showInfillResults(results []infillResult, hist *history) {
+	if len(results) == 0 {
+		return
+	}
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		lines = append(lines, fmt.Sprintf("%-40s | %-8s | Land %3.0f%% of value, $%11s | %9s sf | %s",
+			r.SitusAddress, r.Kind, r.LandShare, formatThousands(r.Land), formatThousands(r.LotSqFt), strings.Join(r.Reasons, ", ")))
+		queries = append(queries, "acct="+r.AccountNum)
+	}
+	interactivePagedSelect(queries, lines, hist, true, resultPageSize)
+}
+
+// showInfill parses "infill [kind=vacant|teardown|both] [lot=<min>-<max>] [share=<pct>]
+// [age=<years>] [filters]" and lists vacant lots and teardown candidates.
+func showInfill(args string, hist *history) {
+	s := defaultInfillSettings()
+	f, err := parseFilterArgs(args, s.option)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: infill [kind=vacant|teardown|both] [lot=<min>-<max> sqft] [share=<land %>] [age=<years>] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+	cur := hist.current()
+	start := time.Now()
+	results := findInfill(cur, f, s)
+	fmt.Printf("\nFound %d %s in %s (%v)\n", len(results), s.label(), f, time.Since(start).Truncate(time.Millisecond))
+	showInfillResults(results, hist)
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestFindInfill(t *testing.T) {
+	props := map[string]Property{
+		"vacant":   {AccountNum: "vacant", SitusAddress: "1 A ST", LandValue: "50000", ImprovementValue: "0", TotalValue: "50000", LandSqFt: "7000"},
+		"old":      {AccountNum: "old", SitusAddress: "2 A ST", LandValue: "80000", ImprovementValue: "20000", TotalValue: "100000", LandSqFt: "7000", YearBuilt: "1940", Condition: "Average"},
+		"poor":     {AccountNum: "poor", SitusAddress: "3 A ST", LandValue: "70000", ImprovementValue: "30000", TotalValue: "100000", LandSqFt: "7000", YearBuilt: "2000", Condition: "Poor"},
+		"sound":    {AccountNum: "sound", SitusAddress: "4 A ST", LandValue: "80000", ImprovementValue: "20000", TotalValue: "100000", LandSqFt: "7000", YearBuilt: "2000", Condition: "Good"},
+		"valuable": {AccountNum: "valuable", SitusAddress: "5 A ST", LandValue: "50000", ImprovementValue: "250000", TotalValue: "300000", LandSqFt: "7000", YearBuilt: "1930", Condition: "Poor"},
+		"big":      {AccountNum: "big", SitusAddress: "6 A ST", LandValue: "90000", ImprovementValue: "1000", TotalValue: "91000", LandSqFt: "40000"},
+		"nolot":    {AccountNum: "nolot", SitusAddress: "7 A ST", LandValue: "40000", ImprovementValue: "0", TotalValue: "40000"},
+		"noimpr":   {AccountNum: "noimpr", SitusAddress: "8 A ST", LandValue: "60000", TotalValue: "60000", LandSqFt: "7000"},
+	}
+	y := &yearData{Year: 2025, ByAcct: props}
+
+	accts := func(rs []infillResult) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.AccountNum)
+		}
+		return out
+	}
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	s := defaultInfillSettings()
+	if got, want := accts(findInfill(y, parcelFilter{}, s)), []string{"vacant", "nolot", "big", "old", "poor"}; !equal(got, want) {
+		t.Errorf("default = %v, want %v", got, want)
+	}
+
+	s.option("kind", "teardown")
+	if got, want := accts(findInfill(y, parcelFilter{}, s)), []string{"old", "poor"}; !equal(got, want) {
+		t.Errorf("kind=teardown = %v, want %v", got, want)
+	}
+
+	s = defaultInfillSettings()
+	s.option("lot", "20000-")
+	if got, want := accts(findInfill(y, parcelFilter{}, s)), []string{"big"}; !equal(got, want) {
+		t.Errorf("lot=20000- = %v, want %v", got, want)
+	}
+
+	// A lot of unknown size fits no lot= range, not even one without a minimum.
+	s = defaultInfillSettings()
+	s.option("lot", "-10000")
+	if got, want := accts(findInfill(y, parcelFilter{}, s)), []string{"vacant", "old", "poor"}; !equal(got, want) {
+		t.Errorf("lot=-10000 = %v, want %v", got, want)
+	}
+}
@@ -9,12 +9,86 @@ import (
 	"golang.org/x/term"
 )
 
+// resultPageSize is how many lines interactivePagedSelect shows per page.
+const resultPageSize = 20
+
 // interactiveSelect lets user move through the provided lines with arrow keys and press Enter to
-// view full property details. It expects len(addresses)==len(lines).
-func interactiveSelect(addresses []string, lines []string, props2025, props2024 map[string]Property, askSave bool) {
-	if len(addresses) == 0 {
+// view full property details. Each query is an address or acct=<Account_Num> passed to
+// lookupAndRender. It expects len(queries)==len(lines). Every line is shown on one page.
+func interactiveSelect(queries []string, lines []string, hist *history, askSave bool) {
+	interactivePagedSelect(queries, lines, hist, askSave, len(lines))
+}
+
+// listKey is a key press understood by the selection list.
+type listKey int
+
+const (
+	keyOther listKey = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyQuit
+)
+
+// readListKey reads one key press in raw mode, decoding both Windows console arrow
+// sequences (0 or 224, then a scan code) and ANSI escape sequences.
+func readListKey(reader *bufio.Reader) (listKey, error) {
+	b1, err := reader.ReadByte()
+	if err != nil {
+		return keyOther, err
+	}
+	switch b1 {
+	case 0, 224:
+		b2, _ := reader.ReadByte()
+		switch b2 {
+		case 72:
+			return keyUp, nil
+		case 80:
+			return keyDown, nil
+		case 75:
+			return keyLeft, nil
+		case 77:
+			return keyRight, nil
+		case 13:
+			return keyEnter, nil
+		}
+	case 27: // ESC or ANSI sequence
+		if reader.Buffered() == 0 {
+			return keyQuit, nil // bare ESC
+		}
+		if b2, _ := reader.ReadByte(); b2 != '[' || reader.Buffered() == 0 {
+			return keyOther, nil // not a CSI sequence; ignore unknown combo
+		}
+		b3, _ := reader.ReadByte()
+		switch b3 {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'D':
+			return keyLeft, nil
+		case 'C':
+			return keyRight, nil
+		}
+	case '\r', '\n':
+		return keyEnter, nil
+	case 3: // Ctrl-C
+		return keyQuit, nil
+	}
+	return keyOther, nil
+}
+
+// interactivePagedSelect is interactiveSelect showing pageSize lines at a time: ↑/↓
+// navigate within a page, ←/→ change pages, Enter shows details, Esc exits.
+func interactivePagedSelect(queries []string, lines []string, hist *history, askSave bool, pageSize int) {
+	if len(queries) == 0 {
 		return
 	}
+	if pageSize <= 0 {
+		pageSize = len(lines)
+	}
 
 	if runtime.GOOS == "windows" {
 		enableVT()
@@ -29,94 +103,63 @@ func interactiveSelect(addresses []string, lines []string, props2025, props2024
 	defer term.Restore(fd, oldState)
 
 	reader := bufio.NewReader(os.Stdin)
-
-	selected := 0
+	page := 0
+	selected := 0 // index within the page
+	totalPages := (len(lines) + pageSize - 1) / pageSize
+	pageLen := func() int { return min(pageSize, len(lines)-page*pageSize) }
 
 	redraw := func() {
 		// Clear screen (ANSI reset to top + clear screen)
 		fmt.Print("\033[H\033[2J")
-		for i, l := range lines {
+		start := page * pageSize
+		for i := start; i < start+pageLen(); i++ {
 			prefix := "  "
-			if i == selected {
+			if i-start == selected {
 				prefix = "> "
 			}
-			fmt.Println(prefix + l)
+			fmt.Println(prefix + lines[i])
+		}
+		if totalPages > 1 {
+			fmt.Printf("(↑/↓ navigate, ←/→ page, Enter details, Esc quit)  Page %d/%d\n", page+1, totalPages)
+		} else {
+			fmt.Println("(↑/↓ to navigate, Enter to view details, Esc to quit)")
 		}
-		fmt.Println("(↑/↓ to navigate, Enter to view details, Esc to quit)")
 	}
 
 	redraw()
 
 	for {
-		b1, err := reader.ReadByte()
+		key, err := readListKey(reader)
 		if err != nil {
 			return
 		}
-		// Handle Windows console arrow sequences (0 or 224, then code)
-		if b1 == 0 || b1 == 224 {
-			b2, _ := reader.ReadByte()
-			switch b2 {
-			case 72: // up
-				if selected > 0 {
-					selected--
-					redraw()
-				}
-			case 80: // down
-				if selected < len(addresses)-1 {
-					selected++
-					redraw()
-				}
-			case 13: // Enter
-				term.Restore(fd, oldState)
-				fmt.Println()
-				lookupAndRender(addresses[selected], props2025, props2024, askSave)
-
-				// Wait for user acknowledgement before returning to list
-				fmt.Print("\n(press Enter to return)")
-				_, _ = bufio.NewReader(os.Stdin).ReadBytes('\n')
-
-				oldState, err = term.MakeRaw(fd)
-				if err != nil {
-					return
-				}
-				reader = bufio.NewReader(os.Stdin)
+		switch key {
+		case keyUp:
+			if selected > 0 {
+				selected--
 				redraw()
 			}
-			continue
-		}
-
-		switch b1 {
-		case 27: // ESC or ANSI sequence
-			if reader.Buffered() == 0 {
-				// Bare ESC – exit
-				fmt.Println()
-				return
-			}
-			b2, _ := reader.ReadByte()
-			if b2 != '[' {
-				// Not a CSI sequence; ignore unknown combo
-				continue
+		case keyDown:
+			if selected < pageLen()-1 {
+				selected++
+				redraw()
 			}
-			if reader.Buffered() == 0 {
-				continue
+		case keyLeft:
+			if page > 0 {
+				page--
+				selected = 0
+				redraw()
 			}
-			b3, _ := reader.ReadByte()
-			switch b3 {
-			case 'A': // up
-				if selected > 0 {
-					selected--
-					redraw()
-				}
-			case 'B': // down
-				if selected < len(addresses)-1 {
-					selected++
-					redraw()
-				}
+		case keyRight:
+			if page < totalPages-1 {
+				page++
+				selected = 0
+				redraw()
 			}
-		case '\r', '\n': // Enter
+		case keyEnter:
 			term.Restore(fd, oldState) // restore cooked mode before rendering details
 			fmt.Println()
-			lookupAndRender(addresses[selected], props2025, props2024, askSave)
+			lookupAndRender(queries[page*pageSize+selected], hist, askSave)
 
 			// Wait for user acknowledgement before returning to list
 			fmt.Print("\n(press Enter to return)")
@@ -127,14 +170,14 @@ func interactiveSelect(addresses []string, lines []string, props2025, props2024
 			if err != nil {
 				return
 			}
+			if runtime.GOOS == "windows" {
+				enableVT()
+			}
 			reader = bufio.NewReader(os.Stdin)
 			redraw()
-		case 3: // Ctrl-C
+		case keyQuit:
 			fmt.Println()
 			return
-
-		default:
-			// ignore other keys
 		}
 	}
 }
@@ -0,0 +1,8 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+// enableVT is a no-op outside Windows, where terminals already interpret ANSI
+// escape sequences.
+func enableVT() {}
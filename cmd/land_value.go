@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ---------------- Undervalued land ----------------
+
+// Defaults for the cheapland command.
+const (
+	defaultLandBelowPct   = 30.0 // how far under the local curve a parcel must sit
+	defaultLandMinAcres   = 0.5  // smaller lots are priced as house sites, not land
+	defaultLandRadiusMi   = 3.0  // how far to look for comparable parcels
+	landMaxComps          = 60   // nearest qualifying parcels used as comparables
+	landMinComps          = 6    // fewer comparables than this and no curve is fitted
+	landSimilarSizeFactor = 4.0  // comparables are within this factor of the subject's acreage
+)
+
+// landValueResult is a parcel whose appraised land $/acre is below what nearby parcels
+// of similar size and zoning would predict.
+type landValueResult struct {
+	Property
+	Acres    float64
+	Land     float64
+	PerAcre  float64
+	Expected float64 // $/acre predicted by the local curve at this acreage
+	Comps    int
+}
+
+// below returns how far under the expected $/acre the parcel sits, in percent.
+func (r landValueResult) below() float64 {
+	return 100 * (1 - r.PerAcre/r.Expected)
+}
+
+// landPoint is one parcel's acreage and land $/acre.
+type landPoint struct {
+	Acres, PerAcre float64
+}
+
+// landPricing returns a parcel's acreage and land value per acre, or ok=false when
+// either is missing.
+func landPricing(p Property) (acres, land float64, ok bool) {
+	acres, ok1 := parcelAcres(p)
+	land, ok2 := parseDollar(p.LandValue)
+	if !ok1 || !ok2 || acres <= 0 || land <= 0 {
+		return 0, 0, false
+	}
+	return acres, land, true
+}
+
+// baseZoneKey returns the dictionary district of the parcel's first base zone, the raw
+// code when the dictionary does not know it, or "" when the parcel has no zoning.
+func baseZoneKey(p Property) string {
+	if len(p.zoning.Base) == 0 {
+		return ""
+	}
+	if key, _, ok := lookupZoningDistrict(p.zoning.Base[0]); ok {
+		return key
+	}
+	return p.zoning.Base[0]
+}
+
+// fitLandCurve fits ln($/acre) = a + b·ln(acres) to the points by least squares and
+// returns the $/acre the curve predicts at acres. Price per acre falls as parcels grow,
+// so a rising slope is treated as noise and flattened, and the slope is capped at -1
+// (where total value would fall with size).
+func fitLandCurve(points []landPoint, acres float64) float64 {
+	var sx, sy float64
+	for _, p := range points {
+		sx += math.Log(p.Acres)
+		sy += math.Log(p.PerAcre)
+	}
+	n := float64(len(points))
+	mx, my := sx/n, sy/n
+	var sxx, sxy float64
+	for _, p := range points {
+		dx := math.Log(p.Acres) - mx
+		sxx += dx * dx
+		sxy += dx * (math.Log(p.PerAcre) - my)
+	}
+	b := 0.0
+	if sxx > 1e-9 {
+		b = math.Max(-1, math.Min(0, sxy/sxx))
+	}
+	return math.Exp(my + b*(math.Log(acres)-mx))
+}
+
+// findUndervaluedLand compares each parcel matching f with at least minAcres against
+// the landMaxComps nearest parcels within radiusMi that share its base zoning and are
+// within landSimilarSizeFactor of its acreage. Parcels whose $/acre is at least belowPct under
+// the size-adjusted local curve are returned, furthest below first.
+func findUndervaluedLand(y *yearData, f parcelFilter, minAcres, belowPct, radiusMi float64) []landValueResult {
+	var results []landValueResult
+	for _, p := range f.parcels(y) {
+		acres, land, ok := landPricing(p)
+		if !ok || acres < minAcres {
+			continue
+		}
+		lat, lon, ok := y.spatial.location(p.AccountNum)
+		if !ok {
+			continue
+		}
+		// Filter every parcel in the radius before keeping the nearest: next to a large
+		// tract the closest parcels are mostly house lots that fail the size test.
+		zone := baseZoneKey(p)
+		var hits []spatialHit
+		for _, hit := range y.spatial.within(lat, lon, radiusMi) {
+			if hit.Acct == p.AccountNum {
+				continue
+			}
+			c := y.ByAcct[hit.Acct]
+			cAcres, _, ok := landPricing(c)
+			if !ok || cAcres < acres/landSimilarSizeFactor || cAcres > acres*landSimilarSizeFactor {
+				continue
+			}
+			if zone != "" && baseZoneKey(c) != zone {
+				continue
+			}
+			hits = append(hits, hit)
+		}
+		sort.Slice(hits, func(i, j int) bool { return hits[i].Miles < hits[j].Miles })
+		if len(hits) > landMaxComps {
+			hits = hits[:landMaxComps]
+		}
+		var comps []landPoint
+		for _, hit := range hits {
+			cAcres, cLand, _ := landPricing(y.ByAcct[hit.Acct])
+			comps = append(comps, landPoint{Acres: cAcres, PerAcre: cLand / cAcres})
+		}
+		if len(comps) < landMinComps {
+			continue
+		}
+		r := landValueResult{
+			Property: p,
+			Acres:    acres,
+			Land:     land,
+			PerAcre:  land / acres,
+			Expected: fitLandCurve(comps, acres),
+			Comps:    len(comps),
+		}
+		if r.below() >= belowPct {
+			results = append(results, r)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if bi, bj := results[i].below(), results[j].below(); bi != bj {
+			return bi > bj
+		}
+		return results[i].SitusAddress < results[j].SitusAddress
+	})
+	return results
+}
+
+// showUndervaluedLand parses "cheapland [below=<pct>] [acres=<min>] [radius=<miles>]
+// [filters]" and lists parcels whose land is appraised well under the local curve.
+func showUndervaluedLand(args string, hist *history) {
+	belowPct, minAcres, radiusMi := defaultLandBelowPct, defaultLandMinAcres, defaultLandRadiusMi
+	f, err := parseFilterArgs(args, func(key, val string) (bool, error) {
+		var dst *float64
+		switch key {
+		case "below":
+			dst = &belowPct
+		case "acres":
+			dst = &minAcres
+		case "radius":
+			dst = &radiusMi
+		default:
+			return false, nil
+		}
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil || v < 0 || (key == "below" && v >= 100) {
+			return true, fmt.Errorf("%s=: invalid number %q", key, val)
+		}
+		*dst = v
+		return true, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: cheapland [below=<pct>] [acres=<min>] [radius=<miles>] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+
+	start := time.Now()
+	results := findUndervaluedLand(hist.current(), f, minAcres, belowPct, radiusMi)
+	fmt.Printf("\nFound %d parcels of ≥%g acres with land $/acre ≥%g%% under comparable parcels within %g mi in %s (%v)\n",
+		len(results), minAcres, belowPct, radiusMi, f, time.Since(start).Truncate(time.Millisecond))
+	if len(results) == 0 {
+		return
+	}
+
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		lines = append(lines, fmt.Sprintf("%-40s | %7.2f ac | $%9s/ac vs $%9s/ac | %3.0f%% below | %2d comps",
+			r.SitusAddress, r.Acres, formatThousands(r.PerAcre), formatThousands(r.Expected), r.below(), r.Comps))
+		queries = append(queries, "acct="+r.AccountNum)
+	}
+	interactivePagedSelect(queries, lines, hist, true, resultPageSize)
+}
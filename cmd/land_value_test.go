@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestFitLandCurve(t *testing.T) {
+	// $/acre = 100000 · acres^-0.5 exactly.
+	var pts []landPoint
+	for _, a := range []float64{1, 2, 4, 8, 16} {
+		pts = append(pts, landPoint{Acres: a, PerAcre: 100000 / math.Sqrt(a)})
+	}
+	if got := fitLandCurve(pts, 9); math.Abs(got-100000.0/3) > 1e-6 {
+		t.Errorf("fitLandCurve at 9 acres = %g, want %g", got, 100000.0/3)
+	}
+
+	// A rising curve is flattened to the geometric mean.
+	rising := []landPoint{{1, 1000}, {4, 4000}}
+	if got := fitLandCurve(rising, 100); math.Abs(got-2000) > 1e-6 {
+		t.Errorf("rising curve predicted %g, want 2000", got)
+	}
+
+	// Identical acreage gives no slope to fit.
+	flat := []landPoint{{2, 1000}, {2, 9000}}
+	if got := fitLandCurve(flat, 5); math.Abs(got-3000) > 1e-6 {
+		t.Errorf("single-size comps predicted %g, want 3000", got)
+	}
+}
+
+func TestFindUndervaluedLandPastSmallLots(t *testing.T) {
+	// A 20-acre tract at $10,000/acre ringed by 80 quarter-mile-close house lots, with
+	// eight 20-acre tracts at $50,000/acre one to two miles out.
+	props := map[string]Property{}
+	add := func(acct string, lat, lon float64, acres, land string) {
+		props[acct] = Property{AccountNum: acct, SitusAddress: acct, LandAcres: acres, LandValue: land,
+			Latitude: fmt.Sprintf("%.6f", lat), Longitude: fmt.Sprintf("%.6f", lon)}
+	}
+	add("tract", 32.7, -97.3, "20", "200000")
+	for i := 0; i < 80; i++ {
+		a := 2 * math.Pi * float64(i) / 80
+		add(fmt.Sprintf("lot%02d", i), 32.7+0.003*math.Sin(a), -97.3+0.003*math.Cos(a), "0.15", "30000")
+	}
+	for i := 0; i < 8; i++ {
+		a := 2 * math.Pi * float64(i) / 8
+		r := 0.015 + 0.002*float64(i)
+		add(fmt.Sprintf("comp%d", i), 32.7+r*math.Sin(a), -97.3+r*math.Cos(a), "20", "1000000")
+	}
+	y := &yearData{Year: 2025, ByAcct: props, spatial: newGridIndex(props)}
+
+	results := findUndervaluedLand(y, parcelFilter{}, 10, 30, 3)
+	if len(results) != 1 || results[0].AccountNum != "tract" {
+		t.Fatalf("results = %+v, want only the tract", results)
+	}
+	if r := results[0]; r.Comps != 8 || math.Abs(r.below()-80) > 1e-6 {
+		t.Errorf("tract: %d comps, %.1f%% below; want 8 comps, 80%% below", r.Comps, r.below())
+	}
+}
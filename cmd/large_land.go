@@ -1,47 +1,226 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"runtime"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
-
-	"golang.org/x/term"
+	"time"
 )
 
-// ---------------- Large-land remote filter ----------------
+// ---------------- Large-land search ----------------
+
+// largeLandFile holds the bigland defaults. It is created on first use and re-read on
+// every run; options given with the command override it for that run.
+var largeLandFile = filepath.Join("data", "bigland.json")
+
+// referencePoint is a named location distances can be measured from.
+type referencePoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// largeLandSettings are the bigland search parameters. A zero maximum means no maximum.
+type largeLandSettings struct {
+	MinAcres   float64                   `json:"min_acres"`
+	MaxAcres   float64                   `json:"max_acres"`
+	MinMiles   float64                   `json:"min_miles"`
+	MaxMiles   float64                   `json:"max_miles"`
+	From       string                    `json:"from"`       // reference point distances are measured from
+	References map[string]referencePoint `json:"references"` // named points usable with from=
+	Sort       string                    `json:"sort"`       // acres, dist, ppa or value
+
+	// Set per run only.
+	Ref      referencePoint `json:"-"`
+	UseCodes []string       `json:"-"` // state or TAD land use code prefixes, any of
+	MinValue float64        `json:"-"` // land value range
+	MaxValue float64        `json:"-"`
+}
+
+// defaultLargeLandSettings reproduces the original search: 10 to 200 acres more than ten
+// miles from downtown Fort Worth.
+func defaultLargeLandSettings() largeLandSettings {
+	return largeLandSettings{
+		MinAcres: 10,
+		MaxAcres: 200,
+		MinMiles: 10,
+		From:     "downtown",
+		References: map[string]referencePoint{
+			"downtown": {Lat: downtownLat, Lon: downtownLon},
+		},
+		Sort: "acres",
+	}
+}
+
+// largeLandSorts are the orderings accepted by sort=.
+var largeLandSorts = []string{"acres", "dist", "ppa", "value"}
+
+// loadLargeLandSettings reads largeLandFile over the defaults and resolves its reference point.
+func loadLargeLandSettings() (largeLandSettings, error) {
+	s := defaultLargeLandSettings()
+	if err := loadJSONConfig(largeLandFile, &s); err != nil {
+		return s, err
+	}
+	if err := s.setFrom(s.From); err != nil {
+		return s, fmt.Errorf("%s: %w", largeLandFile, err)
+	}
+	if err := s.setSort(s.Sort); err != nil {
+		return s, fmt.Errorf("%s: %w", largeLandFile, err)
+	}
+	return s, nil
+}
+
+// setFrom selects the reference point by name or as "<lat>,<lon>".
+func (s *largeLandSettings) setFrom(val string) error {
+	val = strings.TrimSpace(val)
+	for name, ref := range s.References {
+		if strings.EqualFold(name, val) {
+			s.From, s.Ref = name, ref
+			return nil
+		}
+	}
+	if lat, lon, ok := strings.Cut(val, ","); ok {
+		if latV, lonV, ok := parseLatLon(lat, lon); ok {
+			s.From, s.Ref = fmt.Sprintf("(%.6f, %.6f)", latV, lonV), referencePoint{Lat: latV, Lon: lonV}
+			return nil
+		}
+	}
+	names := make([]string, 0, len(s.References))
+	for name := range s.References {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("from=: unknown reference point %q (use %s or <lat>,<lon>)", val, strings.Join(names, ", "))
+}
+
+func (s *largeLandSettings) setSort(val string) error {
+	val = strings.ToLower(strings.TrimSpace(val))
+	for _, o := range largeLandSorts {
+		if val == o {
+			s.Sort = val
+			return nil
+		}
+	}
+	return fmt.Errorf("sort=: unknown order %q (use %s)", val, strings.Join(largeLandSorts, ", "))
+}
+
+// option applies one bigland command option, reporting whether key was one of them.
+func (s *largeLandSettings) option(key, val string) (bool, error) {
+	var err error
+	switch key {
+	case "acres":
+		s.MinAcres, s.MaxAcres, err = parseRange(key, val)
+	case "dist":
+		s.MinMiles, s.MaxMiles, err = parseRange(key, val)
+	case "value":
+		s.MinValue, s.MaxValue, err = parseRange(key, val)
+	case "from":
+		err = s.setFrom(val)
+	case "sort":
+		err = s.setSort(val)
+	case "use":
+		s.UseCodes = nil
+		for _, c := range strings.Split(val, ",") {
+			if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+				s.UseCodes = append(s.UseCodes, c)
+			}
+		}
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// parseRange parses "<min>-<max>", "<min>-", "-<max>" or a bare minimum. A missing
+// maximum is returned as 0.
+func parseRange(key, val string) (lo, hi float64, err error) {
+	loStr, hiStr, _ := strings.Cut(strings.ReplaceAll(val, ",", ""), "-")
+	if strings.TrimSpace(loStr) != "" {
+		if lo, err = strconv.ParseFloat(strings.TrimSpace(loStr), 64); err != nil || lo < 0 {
+			return 0, 0, fmt.Errorf("%s=: invalid range %q (use <min>-<max>)", key, val)
+		}
+	}
+	if strings.TrimSpace(hiStr) != "" {
+		if hi, err = strconv.ParseFloat(strings.TrimSpace(hiStr), 64); err != nil || hi < lo {
+			return 0, 0, fmt.Errorf("%s=: invalid range %q (use <min>-<max>)", key, val)
+		}
+	}
+	return lo, hi, nil
+}
+
+// inRange reports whether v lies in [lo, hi], where hi == 0 means no maximum.
+func inRange(v, lo, hi float64) bool {
+	return v >= lo && (hi == 0 || v <= hi)
+}
+
+// matchesUse reports whether the parcel's state or TAD land use code starts with one of codes.
+func matchesUse(p Property, codes []string) bool {
+	for _, c := range codes {
+		if strings.HasPrefix(strings.ToUpper(p.StateUseCode), c) || strings.HasPrefix(strings.ToUpper(p.LandUseCode), c) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe summarizes the settings for the result header.
+func (s largeLandSettings) describe() string {
+	rng := func(lo, hi float64, unit string) string {
+		if hi == 0 {
+			return fmt.Sprintf("≥%g %s", lo, unit)
+		}
+		return fmt.Sprintf("%g-%g %s", lo, hi, unit)
+	}
+	parts := []string{rng(s.MinAcres, s.MaxAcres, "acres"), rng(s.MinMiles, s.MaxMiles, "mi from "+s.From)}
+	if len(s.UseCodes) > 0 {
+		parts = append(parts, "use "+strings.Join(s.UseCodes, ","))
+	}
+	if s.MaxValue > 0 {
+		parts = append(parts, fmt.Sprintf("land value $%s-$%s", formatThousands(s.MinValue), formatThousands(s.MaxValue)))
+	} else if s.MinValue > 0 {
+		parts = append(parts, fmt.Sprintf("land value ≥$%s", formatThousands(s.MinValue)))
+	}
+	return strings.Join(parts, ", ")
+}
 
-// largeLandResult holds the parcel along with parsed acreage and distance from the reference point.
+// largeLandResult holds the parcel along with parsed acreage, land value and distance
+// from the reference point.
 type largeLandResult struct {
 	Property
 	Acres    float64
 	Distance float64
+	Land     float64
+	PerAcre  float64
 }
 
-// findLargeLandFar returns properties that have at least minAcres of land and are located more than
-// minMiles away from the provided reference latitude/longitude.
-func findLargeLandFar(props map[string]Property, minAcres float64, maxAcres float64, refLat, refLon, minMiles float64) []largeLandResult {
+// findLargeLand returns parcels matching f whose acreage, distance from the reference
+// point, land use and land value fall within s, ordered by s.Sort.
+func findLargeLand(y *yearData, f parcelFilter, s largeLandSettings) []largeLandResult {
 	var results []largeLandResult
 
-	for _, p := range props {
+	for _, p := range f.parcels(y) {
 		// Parse acreage – ignore blank/unparseable values.
-		acresStr := strings.ReplaceAll(strings.TrimSpace(p.LandAcres), ",", "")
-		acres, err := strconv.ParseFloat(acresStr, 64)
-		if err != nil || acres < minAcres || acres > maxAcres {
+		acres, ok := parcelAcres(p)
+		if !ok || !inRange(acres, s.MinAcres, s.MaxAcres) || acres == 0 {
+			continue
+		}
+		if len(s.UseCodes) > 0 && !matchesUse(p, s.UseCodes) {
+			continue
+		}
+		land, _ := parseDollar(p.LandValue)
+		if !inRange(land, s.MinValue, s.MaxValue) {
 			continue
 		}
 
 		// Need valid coordinates to compute distance.
-		lat, lon, ok := parseLatLon(p.Latitude, p.Longitude)
+		lat, lon, ok := y.spatial.location(p.AccountNum)
 		if !ok {
 			continue
 		}
 
-		dist := distanceMiles(refLat, refLon, lat, lon)
-		if dist <= minMiles {
+		dist := distanceMiles(s.Ref.Lat, s.Ref.Lon, lat, lon)
+		if !inRange(dist, s.MinMiles, s.MaxMiles) {
 			continue
 		}
 
@@ -49,196 +228,67 @@ func findLargeLandFar(props map[string]Property, minAcres float64, maxAcres floa
 			Property: p,
 			Acres:    acres,
 			Distance: dist,
+			Land:     land,
+			PerAcre:  land / acres,
 		})
 	}
 
-	// Order by acreage (desc) then distance (asc) for nicer display.
 	sort.Slice(results, func(i, j int) bool {
-		if results[i].Acres == results[j].Acres {
-			return results[i].Distance < results[j].Distance
+		a, b := results[i], results[j]
+		var ka, kb float64
+		switch s.Sort {
+		case "dist":
+			ka, kb = b.Distance, a.Distance // nearest first
+		case "ppa":
+			ka, kb = b.PerAcre, a.PerAcre // cheapest first
+		case "value":
+			ka, kb = a.Land, b.Land
+		default:
+			ka, kb = a.Acres, b.Acres
 		}
-		return results[i].Acres > results[j].Acres
+		if ka != kb {
+			return ka > kb
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.SitusAddress < b.SitusAddress
 	})
 
 	return results
 }
 
-// showLargeLandInteractive finds and lists qualifying properties, allowing the user to select one
-// for detailed viewing via an interactive list where ←/→ switch pages.
-func showLargeLandInteractive(props2025, props2024 map[string]Property) {
-	const (
-		minAcres         = 10.0
-		maxAcres         = 200.0
-		refLat   float64 = 32.760089
-		refLon   float64 = -97.319828
-		minMiles         = 10.0
-	)
-
-	results := findLargeLandFar(props2025, minAcres, maxAcres, refLat, refLon, minMiles)
-	fmt.Printf("\nFound %d properties with >%.0f acres located more than %.0f miles from (%.6f, %.6f)\n", len(results), minAcres, minMiles, refLat, refLon)
-	if len(results) == 0 {
+// showLargeLandInteractive parses "bigland [acres=<min>-<max>] [dist=<min>-<max>]
+// [from=<name>|<lat>,<lon>] [use=<codes>] [value=<min>-<max>] [sort=acres|dist|ppa|value]
+// [filters]" over the settings file and lists qualifying parcels in an interactive list
+// where ←/→ switch pages.
+func showLargeLandInteractive(args string, hist *history) {
+	s, err := loadLargeLandSettings()
+	if err != nil {
+		fmt.Printf("Failed to load bigland settings: %v\n", err)
 		return
 	}
-
-	interactiveLargeLand(results, props2025, props2024)
-}
-
-// interactiveLargeLand presents a paginated list (20 per page) of large-land results.
-// ↑/↓ navigate within a page, ←/→ change pages, Enter shows details, Esc exits.
-func interactiveLargeLand(results []largeLandResult, props2025, props2024 map[string]Property) {
-	const pageSize = 20
-
-	if len(results) == 0 {
+	f, err := parseFilterArgs(args, s.option)
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: bigland [acres=<min>-<max>] [dist=<min>-<max>] [from=<name>|<lat>,<lon>] [use=<codes>] [value=<min>-<max>] [sort=acres|dist|ppa|value] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
 		return
 	}
 
-	if runtime.GOOS == "windows" {
-		enableVT()
-	}
-
-	fd := int(os.Stdin.Fd())
-	oldState, err := term.MakeRaw(fd)
-	if err != nil {
-		fmt.Println("(interactive selection not supported on this terminal)")
+	start := time.Now()
+	results := findLargeLand(hist.current(), f, s)
+	fmt.Printf("\nFound %d properties of %s in %s, by %s (%v)\n",
+		len(results), s.describe(), f, s.Sort, time.Since(start).Truncate(time.Millisecond))
+	if len(results) == 0 {
 		return
 	}
-	defer term.Restore(fd, oldState)
-
-	reader := bufio.NewReader(os.Stdin)
-	page := 0
-	selected := 0
-	totalPages := (len(results) + pageSize - 1) / pageSize
-
-	redraw := func() {
-		fmt.Print("\033[H\033[2J")
-		start := page * pageSize
-		end := start + pageSize
-		if end > len(results) {
-			end = len(results)
-		}
-		for i := start; i < end; i++ {
-			line := fmt.Sprintf("%-40s | Acres: %5.1f | Dist: %4.1f mi", results[i].SitusAddress, results[i].Acres, results[i].Distance)
-			prefix := "  "
-			if i-start == selected {
-				prefix = "> "
-			}
-			fmt.Println(prefix + line)
-		}
-		fmt.Printf("(↑/↓ navigate, ←/→ page, Enter details, Esc quit)  Page %d/%d\n", page+1, totalPages)
-	}
-
-	redraw()
-
-	for {
-		b1, err := reader.ReadByte()
-		if err != nil {
-			return
-		}
-
-		// Handle Windows console arrow sequences (0 or 224 prefix)
-		if b1 == 0 || b1 == 224 {
-			b2, _ := reader.ReadByte()
-			switch b2 {
-			case 72: // up
-				if selected > 0 {
-					selected--
-					redraw()
-				}
-			case 80: // down
-				pageStart := page * pageSize
-				pageLen := pageSize
-				if pageStart+pageLen > len(results) {
-					pageLen = len(results) - pageStart
-				}
-				if selected < pageLen-1 {
-					selected++
-					redraw()
-				}
-			case 75: // left
-				if page > 0 {
-					page--
-					selected = 0
-					redraw()
-				}
-			case 77: // right
-				if page < totalPages-1 {
-					page++
-					selected = 0
-					redraw()
-				}
-			case 13: // Enter (handled later as well)
-			}
-			continue
-		}
 
-		switch b1 {
-		case 27: // ESC or ANSI sequence
-			if reader.Buffered() == 0 {
-				fmt.Println()
-				return
-			}
-			b2, _ := reader.ReadByte()
-			if b2 != '[' {
-				continue
-			}
-			if reader.Buffered() == 0 {
-				continue
-			}
-			b3, _ := reader.ReadByte()
-			switch b3 {
-			case 'A': // up
-				if selected > 0 {
-					selected--
-					redraw()
-				}
-			case 'B': // down
-				pageStart := page * pageSize
-				pageLen := pageSize
-				if pageStart+pageLen > len(results) {
-					pageLen = len(results) - pageStart
-				}
-				if selected < pageLen-1 {
-					selected++
-					redraw()
-				}
-			case 'D': // left
-				if page > 0 {
-					page--
-					selected = 0
-					redraw()
-				}
-			case 'C': // right
-				if page < totalPages-1 {
-					page++
-					selected = 0
-					redraw()
-				}
-			}
-		case '\r', '\n': // Enter
-			idx := page*pageSize + selected
-			if idx < len(results) {
-				term.Restore(fd, oldState)
-				fmt.Println()
-				lookupAndRender(results[idx].SitusAddress, props2025, props2024, true)
-
-				fmt.Print("\n(press Enter to return)")
-				_, _ = bufio.NewReader(os.Stdin).ReadBytes('\n')
-
-				oldState, err = term.MakeRaw(fd)
-				if err != nil {
-					return
-				}
-				if runtime.GOOS == "windows" {
-					enableVT()
-				}
-				reader = bufio.NewReader(os.Stdin)
-				redraw()
-			}
-		case 3: // Ctrl-C
-			fmt.Println()
-			return
-		default:
-			// ignore other keys
-		}
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		lines = append(lines, fmt.Sprintf("%-40s | Acres: %6.1f | Dist: %4.1f mi | Land $%11s | $%9s/ac",
+			r.SitusAddress, r.Acres, r.Distance, formatThousands(r.Land), formatThousands(r.PerAcre)))
+		queries = append(queries, "acct="+r.AccountNum)
 	}
+	interactivePagedSelect(queries, lines, hist, true, resultPageSize)
 }
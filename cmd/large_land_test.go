@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		val     string
+		lo, hi  float64
+		wantErr bool
+	}{
+		{"10-200", 10, 200, false},
+		{"5-", 5, 0, false},
+		{"-25", 0, 25, false},
+		{"2.5", 2.5, 0, false},
+		{"100,000-250,000", 100000, 250000, false},
+		{"20-10", 0, 0, true},
+		{"ten", 0, 0, true},
+	}
+	for _, tt := range tests {
+		lo, hi, err := parseRange("acres", tt.val)
+		if (err != nil) != tt.wantErr || lo != tt.lo || hi != tt.hi {
+			t.Errorf("parseRange(%q) = %g, %g, %v; want %g, %g, error %v", tt.val, lo, hi, err, tt.lo, tt.hi, tt.wantErr)
+		}
+	}
+}
+
+func TestLargeLandSettingsFrom(t *testing.T) {
+	s := defaultLargeLandSettings()
+	s.References["Alliance"] = referencePoint{Lat: 32.99, Lon: -97.32}
+	if err := s.setFrom("alliance"); err != nil || s.From != "Alliance" || s.Ref.Lat != 32.99 {
+		t.Errorf("from=alliance: %v, got %s %+v", err, s.From, s.Ref)
+	}
+	if err := s.setFrom("32.7,-97.4"); err != nil || s.Ref != (referencePoint{Lat: 32.7, Lon: -97.4}) {
+		t.Errorf("from=<lat>,<lon>: %v, got %+v", err, s.Ref)
+	}
+	if err := s.setFrom("nowhere"); err == nil {
+		t.Error("unknown reference point accepted")
+	}
+}
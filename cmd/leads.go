@@ -186,19 +186,19 @@ func createLeadDetailFile(prop Property) error {
 	fmt.Fprintf(&b, "- Living Area (sf): %s\n", prop.LivingArea)
 	bedsBaths := strings.TrimSpace(fmt.Sprintf("%s/%s", prop.NumBedrooms, prop.NumBathrooms))
 	fmt.Fprintf(&b, "- Bedrooms/Bath: %s\n", bedsBaths)
-	// Determine zoning via shapefile lookup (same logic as property renderer)
-	zoningCode := ""
-	if latDeg, lonDeg, ok := parseLatLon(prop.Latitude, prop.Longitude); ok && len(zoningFeatures) > 0 {
-		latFt, lonFt := wgs84ToTxNC(latDeg, lonDeg)
-		if attrs, found := findZoningAttributes(latFt, lonFt); found {
-			if z, ok := attrs["ZONING"]; ok && strings.TrimSpace(z) != "" {
-				zoningCode = strings.TrimSpace(z)
-			} else if z, ok := attrs["BASE_ZONIN"]; ok && strings.TrimSpace(z) != "" {
-				zoningCode = strings.TrimSpace(z)
-			}
+	// Zoning via the same shapefile lookup as the property renderer
+	if z, ok := zoningForProperty(prop); ok {
+		for _, l := range z.lines() {
+			fmt.Fprintf(&b, "- %s: %s\n", l[0], l[1])
+		}
+	} else {
+		fmt.Fprintln(&b, "- Zoning: ")
+	}
+	if shape, ok := parcelGeometry(prop.AccountNum); ok {
+		for _, l := range shape.lines() {
+			fmt.Fprintf(&b, "- %s: %s\n", l[0], l[1])
 		}
 	}
-	fmt.Fprintf(&b, "- Zoning: %s\n", zoningCode)
 	fmt.Fprintf(&b, "- Site Class: %s\n", prop.SiteClassDescr)
 	fmt.Fprintf(&b, "- TAD URL: https://www.tad.org/property?account=%s\n", prop.AccountNum)
 
@@ -250,7 +250,7 @@ func buildOwnerAddress(p Property) string {
 
 // showLeads loads the saved leads and presents them in an interactive list similar to
 // search results. The user can select a lead to view full property details.
-func showLeads(props2025, props2024 map[string]Property) {
+func showLeads(hist *history) {
 	addresses, err := loadLeads()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load leads: %v\n", err)
@@ -263,12 +263,12 @@ func showLeads(props2025, props2024 map[string]Property) {
 
 	var lines []string
 	for _, addr := range addresses {
-		norm := normalize(addr)
 		owner := ""
-		if p, ok := props2025[norm]; ok {
-			owner = p.OwnerName
-		} else if p, ok := props2024[norm]; ok {
-			owner = p.OwnerName
+		if props, _, ok := hist.findByAddress(normalize(addr)); ok {
+			owner = props[0].OwnerName
+			if len(props) > 1 {
+				owner = fmt.Sprintf("%s (+%d more parcels)", owner, len(props)-1)
+			}
 		}
 		line := fmt.Sprintf("%-40s | %s", addr, owner)
 		lines = append(lines, line)
@@ -276,5 +276,5 @@ func showLeads(props2025, props2024 map[string]Property) {
 	}
 
 	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
-	interactiveSelect(addresses, lines, props2025, props2024, false)
+	interactiveSelect(addresses, lines, hist, false)
 }
@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -18,6 +18,7 @@ import (
 type Property struct {
 	AccountNum     string
 	SitusAddress   string
+	SitusZip       string
 	OwnerName      string
 	OwnerAddress   string
 	OwnerCityState string
@@ -55,19 +56,18 @@ type Property struct {
 
 	Latitude  string
 	Longitude string
-}
 
-// Dataset paths. Adjust if your directory layout changes.
-var (
-	primaryFile      = filepath.Join("data", "PropertyData_R_2025.txt")
-	supplementalFile = filepath.Join("data", "PropertyDataSupplemental_R_2025.txt")
-	primary2024File  = filepath.Join("data", "PropertyData_2024.txt")
-)
+	// zoning comes from the zoning shapefiles and is filled in by attachZoning when the year
+	// is first used. It is unexported so gob leaves it out of the snapshot: encoding/gob
+	// ignores struct tags, so a gob:"-" tag would not keep it out.
+	zoning parcelZoning
+}
 
 const (
-	colorRed   = "\033[31m"
-	colorGreen = "\033[32m"
-	colorReset = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorReset  = "\033[0m"
 )
 
 func main() {
@@ -77,90 +77,222 @@ func main() {
 	if err := initZoning(); err != nil {
 		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
 	}
+	if err := loadZoningCodes(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: zoning dictionary: %v\n", err)
+	}
+	if err := initParcels(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
 	datasetStart := time.Now()
 
 	// Load datasets
-	props2025, props2024, err := loadDatasets()
+	hist, err := loadDatasets()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load datasets: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("Datasets loaded in %v (%d records)\n", time.Since(datasetStart).Truncate(time.Millisecond), len(props2025))
+	fmt.Printf("Datasets loaded in %v (%d records, years %s)\n", time.Since(datasetStart).Truncate(time.Millisecond), len(hist.current().ByAcct), formatYears(hist.Years))
 
 	// If the user provided an argument on the command line, decide whether it's a zip or an address.
-	if len(os.Args) > 1 {
-		arg := os.Args[1]
-		// Special command: list large rural parcels (>10 acres & >10mi from downtown)
-		if strings.EqualFold(arg, "bigland") {
-			showLargeLandInteractive(props2025, props2024)
-			return
-		}
-		if strings.HasPrefix(arg, "sub=") || strings.HasPrefix(arg, "sub:") {
-			sub := strings.TrimPrefix(strings.TrimPrefix(arg, "sub="), "sub:")
-			handleSubdivisionQuery(sub, props2025, props2024)
-			return
-		}
-		// Otherwise treat the argument(s) as an address lookup.
-		address := strings.Join(os.Args[1:], " ")
-		lookupAndRender(address, props2025, props2024, true)
+	args := os.Args[1:]
+	// A leading year=<YYYY> selects which loaded year is treated as current.
+	if len(args) > 0 && strings.HasPrefix(args[0], "year=") {
+		selectYear(strings.TrimPrefix(args[0], "year="), hist)
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		runCommand(strings.Join(args, " "), hist)
 		return
 	}
 
 	// Interactive loop for multiple lookups.
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print("Enter address, sub=<Subdivision>, 'leads', or 'bigland' (blank to quit): ")
+		fmt.Printf("[%d] Enter address, sub=<Subdivision>, acct=<Account_Num>, owner=<Name>, comps <address>, distressed [zip=|city=|isd=|near=], changes [from=|to=], declines, upside, assemble, cheapland, infill, year=<YYYY>, 'leads', or 'bigland [acres=|dist=|from=|sort=]' (blank to quit): ", hist.Active)
 		input, _ := reader.ReadString('\n')
 		addrInput := strings.TrimSpace(input)
 		if addrInput == "" {
 			break
 		}
-		// Special command: show leads list
-		if strings.EqualFold(addrInput, "leads") {
-			showLeads(props2025, props2024)
-			continue
-		}
-		// Special command: list large rural parcels (>10 acres & >10mi from downtown)
-		if strings.EqualFold(addrInput, "bigland") {
-			showLargeLandInteractive(props2025, props2024)
-			continue
-		}
+		runCommand(addrInput, hist)
+	}
+}
 
-		// Subdivision query
-		if strings.HasPrefix(addrInput, "sub=") || strings.HasPrefix(addrInput, "sub:") {
-			sub := strings.TrimPrefix(strings.TrimPrefix(addrInput, "sub="), "sub:")
-			handleSubdivisionQuery(sub, props2025, props2024)
-			continue
+// runCommand dispatches one query from the command line or the interactive prompt.
+func runCommand(input string, hist *history) {
+	// Special command: show leads list
+	if strings.EqualFold(input, "leads") {
+		showLeads(hist)
+		return
+	}
+	// Special command: list large parcels (by default 10-200 acres more than 10mi from downtown)
+	if lower := strings.ToLower(input); lower == "bigland" || strings.HasPrefix(lower, "bigland ") {
+		showLargeLandInteractive(input[len("bigland"):], hist)
+		return
+	}
+
+	// Switch the active year
+	if strings.HasPrefix(input, "year=") {
+		selectYear(strings.TrimPrefix(input, "year="), hist)
+		return
+	}
+
+	// Subdivision query, optionally narrowed by further filters ("sub=X occ=outofstate")
+	if strings.HasPrefix(input, "sub=") || strings.HasPrefix(input, "sub:") {
+		f, err := parseParcelFilter("sub=" + input[len("sub="):])
+		if err != nil {
+			fmt.Println(err)
+			return
 		}
+		handleSubdivisionQuery(f, hist)
+		return
+	}
+
+	// Owner portfolio query
+	if strings.HasPrefix(input, "owner=") || strings.HasPrefix(input, "owner:") {
+		name := strings.TrimPrefix(strings.TrimPrefix(input, "owner="), "owner:")
+		showOwnerPortfolio(name, hist)
+		return
+	}
 
-		// Default: treat input as an address search
-		lookupAndRender(addrInput, props2025, props2024, true)
+	// Distressed filter over any geography
+	if lower := strings.ToLower(input); lower == "distressed" || strings.HasPrefix(lower, "distressed ") {
+		handleDistressedCommand(input[len("distressed"):], hist)
+		return
+	}
+
+	// Year-over-year change feed
+	if lower := strings.ToLower(input); lower == "changes" || strings.HasPrefix(lower, "changes ") {
+		showChanges(input[len("changes"):], hist)
+		return
+	}
+
+	// Condition / quality / depreciation declines
+	if lower := strings.ToLower(input); lower == "declines" || strings.HasPrefix(lower, "declines ") {
+		showDeclines(input[len("declines"):], hist)
+		return
+	}
+
+	// Parcels zoned for more housing than they hold
+	if lower := strings.ToLower(input); lower == "upside" || strings.HasPrefix(lower, "upside ") {
+		showUpside(input[len("upside"):], hist)
+		return
+	}
+
+	// Clusters of adjoining parcels that could be bought together
+	if lower := strings.ToLower(input); lower == "assemble" || strings.HasPrefix(lower, "assemble ") {
+		showAssemblages(input[len("assemble"):], hist)
+		return
+	}
+
+	// Land appraised well under nearby parcels of similar size and zoning
+	if lower := strings.ToLower(input); lower == "cheapland" || strings.HasPrefix(lower, "cheapland ") {
+		showUndervaluedLand(input[len("cheapland"):], hist)
+		return
+	}
+
+	// Vacant lots and teardown candidates
+	if lower := strings.ToLower(input); lower == "infill" || strings.HasPrefix(lower, "infill ") {
+		showInfill(input[len("infill"):], hist)
+		return
+	}
+
+	// Comparable properties for one subject
+	if lower := strings.ToLower(input); lower == "comps" || strings.HasPrefix(lower, "comps ") {
+		showComps(input[len("comps"):], hist)
+		return
+	}
+
+	// Default: treat input as an address or acct= search
+	lookupAndRender(input, hist, true)
+}
+
+// selectYear parses a year and makes it the active one, reporting the loaded years on failure.
+func selectYear(s string, hist *history) {
+	year, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || !hist.setActive(year) {
+		fmt.Printf("Year %q not loaded; available years: %s\n", s, formatYears(hist.Years))
+		return
+	}
+	if prev := hist.prior(year); prev != nil {
+		fmt.Printf("Active year set to %d (comparing against %d)\n", year, prev.Year)
+	} else {
+		fmt.Printf("Active year set to %d (no earlier year loaded)\n", year)
 	}
 }
 
-// lookupAndRender searches the 2025 and 2024 maps for the given address and displays the result.
-func lookupAndRender(address string, props2025 map[string]Property, props2024 map[string]Property, askSave bool) {
-	norm := normalize(address)
-	prop2025, ok2025 := props2025[norm]
-	prop2024, ok2024 := props2024[norm]
-
-	// selProp points to the Property we ultimately displayed (2025 preferred, else 2024).
-	var selProp *Property
-
-	if ok2025 {
-		selProp = &prop2025
-		if ok2024 {
-			renderPropertyDiff(prop2025, prop2024)
-		} else {
-			renderPropertyDiff(prop2025, Property{})
+// promptFloat asks for a number, returning def when the answer is blank or invalid.
+func promptFloat(reader *bufio.Reader, label string, def float64) float64 {
+	fmt.Printf("%s (default %g): ", label, def)
+	line, _ := reader.ReadString('\n')
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return def
+	}
+	v, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		fmt.Printf("Invalid number %q; using %g\n", line, def)
+		return def
+	}
+	return v
+}
+
+// promptInt is promptFloat for whole numbers.
+func promptInt(reader *bufio.Reader, label string, def int) int {
+	return int(promptFloat(reader, label, float64(def)))
+}
+
+func formatYears(years []int) string {
+	parts := make([]string, len(years))
+	for i, y := range years {
+		parts[i] = strconv.Itoa(y)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lookupAndRender resolves the query – an address or acct=<Account_Num> – in the active year
+// (falling back to other loaded years), displays it diffed against the year before, and lists
+// its full multi-year history. When an address matches several parcels a picker is shown.
+func lookupAndRender(query string, hist *history, askSave bool) {
+	var selProp Property
+	var year int
+	norm := ""
+	if acct, isAcct := accountQuery(query); isAcct {
+		p, y, ok := hist.findByAccount(acct)
+		if !ok {
+			fmt.Printf("No property found for account: %s\n", acct)
+			return
 		}
-	} else if ok2024 {
-		selProp = &prop2024
-		fmt.Println("[Note] No 2025 record found; displaying 2024 data")
-		renderPropertyDiff(prop2024, Property{})
+		selProp, year = p, y
 	} else {
-		fmt.Printf("No property found for address: %s\n", address)
-		return
+		norm = normalize(query)
+		props, y, ok := hist.findByAddress(norm)
+		if !ok {
+			suggestAndPick(query, hist, askSave)
+			return
+		}
+		if len(props) > 1 {
+			pickParcel(props, hist, askSave)
+			return
+		}
+		selProp, year = props[0], y
+	}
+	if year != hist.Active {
+		fmt.Printf("[Note] No %d record found; displaying %d data\n", hist.Active, year)
+	}
+
+	// Compare against the same account in the previous year, falling back to the address
+	// when it identifies a single parcel (accounts are occasionally renumbered).
+	prevYear := hist.prior(year)
+	prev, ok := prevYear.props()[selProp.AccountNum]
+	if !ok && norm != "" {
+		if props := prevYear.lookupAddress(norm); len(props) == 1 {
+			prev = props[0]
+		}
+	}
+	renderPropertyDiff(selProp, prev)
+	if len(hist.Years) > 1 {
+		renderHistory(selProp.AccountNum, hist)
 	}
 
 	if askSave {
@@ -170,7 +302,7 @@ func lookupAndRender(address string, props2025 map[string]Property, props2024 ma
 		resp, _ := reader.ReadString('\n')
 		resp = strings.ToLower(strings.TrimSpace(resp))
 		if resp == "y" || resp == "yes" {
-			if err := saveLead(*selProp); err != nil {
+			if err := saveLead(selProp); err != nil {
 				fmt.Printf("Failed to save lead: %v\n", err)
 			} else {
 				fmt.Println("Lead saved.")
@@ -179,129 +311,6 @@ func lookupAndRender(address string, props2025 map[string]Property, props2024 ma
 	}
 }
 
-// loadDatasets reads both data files, merges them by Account Number, and returns a map keyed by normalized address.
-func loadDatasets() (map[string]Property, map[string]Property, error) {
-	// First read primary file into map keyed by account number.
-	primaryByAcct := make(map[string]Property)
-	var muPrimary sync.Mutex
-	if err := readFile(primaryFile, func(record map[string]string) {
-		acct := record["Account_Num"]
-		prop := Property{
-			AccountNum:       acct,
-			SitusAddress:     record["Situs_Address"],
-			OwnerName:        record["Owner_Name"],
-			OwnerAddress:     record["Owner_Address"],
-			OwnerCityState:   record["Owner_CityState"],
-			OwnerZip:         record["Owner_Zip"],
-			Subdivision:      record["SubdivisionName"],
-			County:           record["County"],
-			City:             record["City"],
-			SchoolDistrict:   record["School"],
-			LandValue:        record["Land_Value"],
-			ImprovementValue: record["Improvement_Value"],
-			TotalValue:       record["Total_Value"],
-
-			DeedDate:     record["Deed_Date"],
-			ARBIndicator: record["ARB_Indicator"],
-
-			YearBuilt:    record["Year_Built"],
-			LivingArea:   record["Living_Area"],
-			NumBedrooms:  record["Num_Bedrooms"],
-			NumBathrooms: record["Num_Bathrooms"],
-
-			PropertyClass: record["Property_Class"],
-			StateUseCode:  record["State_Use_Code"],
-
-			LandAcres: record["Land_Acres"],
-			LandSqFt:  record["Land_SqFt"],
-		}
-		muPrimary.Lock()
-		primaryByAcct[acct] = prop
-		muPrimary.Unlock()
-	}); err != nil {
-		return nil, nil, err
-	}
-
-	// Now read supplemental file and merge.
-	if err := readFile(supplementalFile, func(record map[string]string) {
-		acct := record["AccountNumber"]
-		muPrimary.Lock()
-		prop, ok := primaryByAcct[acct]
-		if !ok {
-			muPrimary.Unlock()
-			// Only merge if we already have the base record.
-			return
-		}
-		prop.Latitude = record["Latitude"]
-		prop.Longitude = record["Longitude"]
-		prop.Quality = record["Quality"]
-
-		prop.LastSaleDate = record["LastSaleDate"]
-		prop.Condition = record["Condition"]
-		prop.DepreciationPercent = record["DepreciationPercent"]
-
-		prop.Subdivision = record["SubdivisionName"]
-		prop.SiteClassCd = record["SiteClassCd"]
-		prop.SiteClassDescr = record["SiteClassDescr"]
-		prop.LandUseCode = record["LandUseCode"]
-		primaryByAcct[acct] = prop
-		muPrimary.Unlock()
-	}); err != nil {
-		return nil, nil, err
-	}
-
-	// Build address map.
-	byAddress := make(map[string]Property, len(primaryByAcct))
-	for _, prop := range primaryByAcct {
-		addrNorm := normalize(prop.SitusAddress)
-		byAddress[addrNorm] = prop
-	}
-	// Now load 2024 primary dataset and build address map.
-	primary2024ByAcct := make(map[string]Property)
-	var mu2024 sync.Mutex
-	if err := readFile(primary2024File, func(record map[string]string) {
-		acct := record["Account_Num"]
-		prop := Property{
-			AccountNum:       acct,
-			SitusAddress:     record["Situs_Address"],
-			OwnerName:        record["Owner_Name"],
-			OwnerAddress:     record["Owner_Address"],
-			OwnerCityState:   record["Owner_CityState"],
-			OwnerZip:         record["Owner_Zip"],
-			Subdivision:      record["SubdivisionName"],
-			County:           record["County"],
-			City:             record["City"],
-			SchoolDistrict:   record["School"],
-			LandValue:        record["Land_Value"],
-			ImprovementValue: record["Improvement_Value"],
-			TotalValue:       record["Total_Value"],
-			DeedDate:         record["Deed_Date"],
-			ARBIndicator:     record["ARB_Indicator"],
-			YearBuilt:        record["Year_Built"],
-			LivingArea:       record["Living_Area"],
-			NumBedrooms:      record["Num_Bedrooms"],
-			NumBathrooms:     record["Num_Bathrooms"],
-			PropertyClass:    record["Property_Class"],
-			StateUseCode:     record["State_Use_Code"],
-			LandAcres:        record["Land_Acres"],
-			LandSqFt:         record["Land_SqFt"],
-		}
-		mu2024.Lock()
-		primary2024ByAcct[acct] = prop
-		mu2024.Unlock()
-	}); err != nil {
-		return nil, nil, err
-	}
-
-	byAddress2024 := make(map[string]Property, len(primary2024ByAcct))
-	for _, prop := range primary2024ByAcct {
-		addrNorm := normalize(prop.SitusAddress)
-		byAddress2024[addrNorm] = prop
-	}
-
-	return byAddress, byAddress2024, nil
-}
-
 // readFile iterates through a |-delimited file with a header row, calling fn for each record.
 func readFile(path string, fn func(record map[string]string)) error {
 	f, err := os.Open(path)
@@ -350,12 +359,31 @@ func readFile(path string, fn func(record map[string]string)) error {
 	return scanner.Err()
 }
 
-// normalize produces a canonical form of an address key.
+// hasColumn reports whether the header row of a |-delimited file names col.
+func hasColumn(path, col string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 1024*1024)
+	if !scanner.Scan() {
+		return false, scanner.Err()
+	}
+	for _, h := range strings.Split(scanner.Text(), "|") {
+		if strings.TrimSpace(h) == col {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// normalize produces a canonical form of an address key: USPS-standardized components in a
+// fixed order, so "123 Main Street" and "123 MAIN ST" share a key.
 func normalize(addr string) string {
-	addr = strings.ToUpper(strings.TrimSpace(addr))
-	addr = strings.ReplaceAll(addr, ",", "")
-	addr = strings.Join(strings.Fields(addr), " ") // collapse whitespace
-	return addr
+	return parseAddress(addr).String()
 }
 
 // renderProperty prints the property information in a pleasant, readable layout.
@@ -371,19 +399,19 @@ func renderPropertyDiff(cur Property, prev Property) {
 	fmt.Printf("Address           : %s\n", cur.SitusAddress)
 	fmt.Printf("Subdivision       : %s%s\n", cur.Subdivision, diff(cur.Subdivision, prev.Subdivision))
 
-	fmt.Printf("Owner             : %s%s\n", cur.OwnerName, diff(cur.OwnerName, prev.OwnerName))
+	fmt.Printf("Owner             : %s [%s]%s\n", cur.OwnerName, classifyOwner(cur.OwnerName), diff(cur.OwnerName, prev.OwnerName))
 	curAddr := fmt.Sprintf("%s, %s %s", cur.OwnerAddress, cur.OwnerCityState, cur.OwnerZip)
 	prevAddr := fmt.Sprintf("%s, %s %s", prev.OwnerAddress, prev.OwnerCityState, prev.OwnerZip)
 
-	norm := func(s string) string { return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), ",", "")) }
-
-	sameTag := ""
-	if norm(cur.OwnerAddress) == norm(cur.SitusAddress) {
-		sameTag = fmt.Sprintf(" %s[Same]%s", colorGreen, colorReset)
+	occ := classifyOccupancy(cur)
+	occColor := colorYellow
+	if !occ.absentee() {
+		occColor = colorGreen
 	}
+	occTag := fmt.Sprintf(" %s[%s]%s", occColor, occ, colorReset)
 
 	diffTag := diff(curAddr, prevAddr)
-	fmt.Printf("Owner Address     : %s%s%s\n", curAddr, sameTag, diffTag)
+	fmt.Printf("Owner Address     : %s%s%s\n", curAddr, occTag, diffTag)
 	fmt.Printf("Last Sale Date    : %s%s\n", cur.LastSaleDate, diff(cur.LastSaleDate, prev.LastSaleDate))
 	fmt.Println()
 
@@ -406,93 +434,231 @@ func renderPropertyDiff(cur Property, prev Property) {
 	fmt.Printf("Site Class        : %s%s\n", cur.SiteClassDescr, diff(cur.SiteClassDescr, prev.SiteClassDescr))
 	fmt.Printf("TAD URL           : https://www.tad.org/property?account=%s\n", cur.AccountNum)
 
-	// Zoning lookup via shapefile
-	latDeg, lonDeg, ok := parseLatLon(cur.Latitude, cur.Longitude)
-	if ok && len(zoningFeatures) > 0 {
-		latFt, lonFt := wgs84ToTxNC(latDeg, lonDeg)
-		if attrs, found := findZoningAttributes(latFt, lonFt); found {
-			if z, ok := attrs["ZONING"]; ok && strings.TrimSpace(z) != "" {
-				fmt.Printf("Zoning            : %s\n", strings.TrimSpace(z))
-			} else if z, ok := attrs["BASE_ZONIN"]; ok && strings.TrimSpace(z) != "" {
-				fmt.Printf("Zoning            : %s\n", strings.TrimSpace(z))
-			} else {
-				fmt.Println("Zoning attributes found but zoning code missing")
-			}
-		} else {
-			fmt.Println("No zoning attributes found")
+	// Zoning lookup via shapefile: base zone and each overlay district on its own line
+	if z, ok := zoningForProperty(cur); ok {
+		for _, l := range z.lines() {
+			fmt.Printf("%-18s: %s\n", l[0], l[1])
 		}
 	} else {
 		fmt.Println("Latitude/Longitude unavailable; cannot determine zoning")
 	}
+	// Lot geometry from the parcel layer, when one is loaded
+	if shape, ok := parcelGeometry(cur.AccountNum); ok {
+		for _, l := range shape.lines() {
+			fmt.Printf("%-18s: %s\n", l[0], l[1])
+		}
+	}
+	fmt.Println(strings.Repeat("-", 80))
+}
+
+// accountQuery reports whether query is of the form acct=<Account_Num> and returns the account.
+func accountQuery(query string) (string, bool) {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(strings.ToLower(query), "acct=") {
+		return "", false
+	}
+	return strings.TrimSpace(query[len("acct="):]), true
+}
+
+// pickParcel lists every parcel sharing one situs address (condos, duplex units, split lots)
+// and lets the user choose which to view.
+func pickParcel(props []Property, hist *history, askSave bool) {
+	fmt.Printf("%d parcels share the address %s:\n", len(props), props[0].SitusAddress)
+	var lines []string
+	var queries []string
+	for _, p := range props {
+		line := fmt.Sprintf("Acct %-10s | %-30s | %-30s | %s sf", p.AccountNum, p.SitusAddress, p.OwnerName, p.LivingArea)
+		lines = append(lines, line)
+		queries = append(queries, "acct="+p.AccountNum)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, askSave)
+}
+
+// suggestAndPick offers the closest fuzzy matches for an address with no exact hit.
+func suggestAndPick(query string, hist *history, askSave bool) {
+	suggestions := suggestAddresses(query, hist.current(), suggestionCount)
+	if len(suggestions) == 0 {
+		fmt.Printf("No property found for address: %s\n", query)
+		return
+	}
+	fmt.Printf("No exact match for %q. Did you mean:\n", query)
+	var lines []string
+	var queries []string
+	for _, sg := range suggestions {
+		owner := ""
+		if props := hist.current().lookupAddress(sg.Address); len(props) > 0 {
+			owner = props[0].OwnerName
+			if len(props) > 1 {
+				owner = fmt.Sprintf("%s (+%d more parcels)", owner, len(props)-1)
+			}
+		}
+		line := fmt.Sprintf("%-40s | %3.0f%% | %s", sg.Address, sg.Score*100, owner)
+		lines = append(lines, line)
+		queries = append(queries, sg.Address)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, askSave)
+}
+
+// renderHistory prints one row per loaded year for the given account so long-run
+// ownership and value trends are visible at a glance. Changed cells are shown in red.
+func renderHistory(acct string, hist *history) {
+	fmt.Printf("History           : account %s\n", acct)
+	fmt.Printf("  %-4s  %12s  %12s  %12s  %-10s  %s\n", "Year", "Total", "Improvement", "Land", "Deed Date", "Owner")
+	var prev *Property
+	for _, year := range hist.Years {
+		p, ok := hist.ByYear[year].ByAcct[acct]
+		if !ok {
+			fmt.Printf("  %-4d  %12s\n", year, "(no record)")
+			prev = nil
+			continue
+		}
+		cell := func(cur, old, format string) string {
+			s := fmt.Sprintf(format, cur)
+			if old != cur {
+				return colorRed + s + colorReset
+			}
+			return s
+		}
+		old := p
+		if prev != nil {
+			old = *prev
+		}
+		fmt.Printf("  %-4d  %s  %s  %s  %s  %s\n", year,
+			cell(p.TotalValue, old.TotalValue, "%12s"),
+			cell(p.ImprovementValue, old.ImprovementValue, "%12s"),
+			cell(p.LandValue, old.LandValue, "%12s"),
+			cell(p.DeedDate, old.DeedDate, "%-10s"),
+			cell(p.OwnerName, old.OwnerName, "%s"))
+		prev = &p
+	}
 	fmt.Println(strings.Repeat("-", 80))
 }
 
 // ---------------- Subdivision undervaluation analysis ----------------
 
+// compMetric selects which per-parcel figure the undervaluation analysis compares.
+type compMetric int
+
+const (
+	metricImpSqft   compMetric = iota + 1 // ImprovementValue / LivingArea
+	metricTotalSqft                       // TotalValue / LivingArea
+	metricLandSqft                        // LandValue / LandSqFt
+	metricRawImp                          // ImprovementValue
+)
+
+func (m compMetric) String() string {
+	switch m {
+	case metricImpSqft:
+		return "improvement $/sqft"
+	case metricTotalSqft:
+		return "total $/sqft"
+	case metricLandSqft:
+		return "land $/sqft"
+	case metricRawImp:
+		return "improvement value"
+	}
+	return "unknown"
+}
+
+// value computes the metric for p, reporting false when the inputs are missing or zero.
+func (m compMetric) value(p Property) (float64, bool) {
+	ratio := func(num, den string) (float64, bool) {
+		n, ok1 := parseDollar(num)
+		d, ok2 := parseDollar(den)
+		if !ok1 || !ok2 || d <= 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	switch m {
+	case metricImpSqft:
+		return ratio(p.ImprovementValue, p.LivingArea)
+	case metricTotalSqft:
+		return ratio(p.TotalValue, p.LivingArea)
+	case metricLandSqft:
+		return ratio(p.LandValue, p.LandSqFt)
+	case metricRawImp:
+		return parseDollar(p.ImprovementValue)
+	}
+	return 0, false
+}
+
+// undervaluedOptions controls the neighbor comparison in undervaluedFromCandidates.
+type undervaluedOptions struct {
+	Metric   compMetric
+	Radius   float64 // miles
+	MinComps int     // fewest neighbors required for a meaningful mean
+	Sigma    float64 // standard deviations below the mean needed to flag a parcel
+}
+
+func defaultUndervaluedOptions() undervaluedOptions {
+	return undervaluedOptions{
+		Metric:   metricImpSqft,
+		Radius:   0.25,
+		MinComps: 3,
+		Sigma:    1.0,
+	}
+}
+
 type undervaluedResult struct {
 	Property
+	Value         float64 // the parcel's own metric value
 	NeighborCount int
 	Mean          float64
 	StdDev        float64
-}
-
-// findUndervaluedInSubdivision returns properties in the given subdivision whose ImprovementValue
-// is at least one standard deviation below neighboring comps (0.25 mi).
-func findUndervaluedInSubdivision(sub string, props map[string]Property) []undervaluedResult {
-	sub = strings.ToUpper(strings.TrimSpace(sub))
-	// Collect candidates in subdivision with coords & value.
-	var candidates []Property
-	for _, p := range props {
-		if strings.ToUpper(strings.TrimSpace(p.Subdivision)) == sub && p.Latitude != "" && p.Longitude != "" && p.ImprovementValue != "" {
-			candidates = append(candidates, p)
-		}
-	}
-	return undervaluedFromCandidates(candidates, props)
+	ZScore        float64 // (Value-Mean)/StdDev; negative means below the neighbors
 }
 
 // undervaluedFromCandidates runs the spatial+stat comparison for a set of candidate
-// properties and returns those that are at least one standard deviation under the mean.
-func undervaluedFromCandidates(candidates []Property, universe map[string]Property) []undervaluedResult {
+// properties and returns those at least opts.Sigma standard deviations under the mean
+// of their neighbors, most undervalued first. Neighbors are drawn from universe via its
+// spatial index; the candidate itself is excluded from its own comps.
+func undervaluedFromCandidates(candidates []Property, universe map[string]Property, idx *gridIndex, opts undervaluedOptions) []undervaluedResult {
 	var results []undervaluedResult
 	for _, p := range candidates {
-		lat1, lon1, ok := parseLatLon(p.Latitude, p.Longitude)
+		lat1, lon1, ok := idx.location(p.AccountNum)
 		if !ok {
 			continue
 		}
-		val, ok := parseDollar(p.ImprovementValue)
+		val, ok := opts.Metric.value(p)
 		if !ok {
 			continue
 		}
 
 		var neighborVals []float64
-		for _, q := range universe {
-			if q.Latitude == "" || q.Longitude == "" || q.ImprovementValue == "" {
-				continue
-			}
-			lat2, lon2, ok := parseLatLon(q.Latitude, q.Longitude)
-			if !ok {
+		for _, hit := range idx.within(lat1, lon1, opts.Radius) {
+			if hit.Acct == p.AccountNum {
 				continue
 			}
-			if distanceMiles(lat1, lon1, lat2, lon2) <= 0.1 {
-				if v, ok := parseDollar(q.ImprovementValue); ok {
-					neighborVals = append(neighborVals, v)
-				}
+			if v, ok := opts.Metric.value(universe[hit.Acct]); ok {
+				neighborVals = append(neighborVals, v)
 			}
 		}
 
-		if len(neighborVals) < 3 { // need a few comps to be meaningful
+		if len(neighborVals) < opts.MinComps { // need a few comps to be meaningful
 			continue
 		}
 		mean, std := meanStd(neighborVals)
-		if val < mean-std {
+		if std == 0 {
+			continue
+		}
+		z := (val - mean) / std
+		if z <= -opts.Sigma {
 			results = append(results, undervaluedResult{
 				Property:      p,
+				Value:         val,
 				NeighborCount: len(neighborVals),
 				Mean:          mean,
 				StdDev:        std,
+				ZScore:        z,
 			})
 		}
 	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ZScore < results[j].ZScore })
 	return results
 }
 
@@ -502,7 +668,14 @@ func parseLatLon(latStr, lonStr string) (float64, float64, bool) {
 	return lat, lon, err1 == nil && err2 == nil
 }
 
+// parseDollar parses a dollar amount as written in the exports.
 func parseDollar(s string) (float64, bool) {
+	return parseNumber(s)
+}
+
+// parseNumber parses a number that may carry thousands separators, such as an acreage
+// or square footage. ok is false for blank or malformed values.
+func parseNumber(s string) (float64, bool) {
 	s = strings.ReplaceAll(s, ",", "")
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ---------------- Owner mailing-address classification ----------------
+
+// occupancy describes where the owner receives mail relative to the parcel.
+type occupancy int
+
+const (
+	occUnknown       occupancy = iota // no mailing address on file
+	occOwnerOccupied                  // mailing address is the situs address
+	occLocalAbsentee                  // mailing address elsewhere in the county
+	occInState                        // mailing address elsewhere in Texas
+	occOutOfState
+	occPOBox // PO Box in Texas; out-of-state boxes are occOutOfState
+)
+
+// occupancyNames are the labels shown in the detail view, in occupancy order.
+var occupancyNames = []string{"Unknown", "Owner-occupied", "Local absentee", "In-state absentee", "Out-of-state", "PO Box"}
+
+func (o occupancy) String() string {
+	if int(o) < len(occupancyNames) {
+		return occupancyNames[o]
+	}
+	return "Unknown"
+}
+
+// absentee reports whether the owner does not receive mail at the parcel.
+func (o occupancy) absentee() bool {
+	return o != occUnknown && o != occOwnerOccupied
+}
+
+// occupancyAliases maps the words accepted by the occ= filter onto classes.
+var occupancyAliases = map[string]occupancy{
+	"occupied": occOwnerOccupied, "owner": occOwnerOccupied,
+	"local": occLocalAbsentee, "unknown": occUnknown,
+	"instate": occInState, "in-state": occInState,
+	"outofstate": occOutOfState, "out-of-state": occOutOfState, "oos": occOutOfState,
+	"pobox": occPOBox, "po": occPOBox,
+}
+
+// homeState is the state every parcel in the county is in.
+const homeState = "TX"
+
+// localZipPrefixes are the three-digit zip prefixes that count as local mail. Tarrant
+// County is served by 760xx and 761xx.
+var localZipPrefixes = []string{"760", "761"}
+
+// poBoxPattern recognizes post office and rural boxes in a mailing street line.
+var poBoxPattern = regexp.MustCompile(`^(?:P\s*O\s*BOX|POST OFFICE BOX|POB|BOX|DRAWER)\s*\d`)
+
+// stateNames maps spelled-out state names that occasionally appear in Owner_CityState.
+var stateNames = map[string]string{"TEXAS": "TX"}
+
+// mailingState extracts the two-letter state from an Owner_CityState value such as
+// "FORT WORTH, TX" or "DENVER CO". It returns "" when no state can be found.
+func mailingState(cityState string) string {
+	fields := strings.Fields(strings.ToUpper(strings.ReplaceAll(cityState, ",", " ")))
+	if len(fields) == 0 {
+		return ""
+	}
+	last := strings.Trim(fields[len(fields)-1], ".")
+	if st, ok := stateNames[last]; ok {
+		return st
+	}
+	if len(last) == 2 && last[0] >= 'A' && last[0] <= 'Z' && last[1] >= 'A' && last[1] <= 'Z' {
+		return last
+	}
+	return ""
+}
+
+// classifyOccupancy compares the owner mailing address with the situs address.
+// Out-of-state takes precedence over PO Box so a box in another state still reads as
+// out-of-state; a Texas box is only "local" or "in-state" if its zip says so.
+func classifyOccupancy(p Property) occupancy {
+	street := strings.TrimSpace(p.OwnerAddress)
+	if street == "" {
+		return occUnknown
+	}
+	mailZip := zip5(p.OwnerZip)
+	situsZip := zip5(p.SitusZip)
+	if normalize(street) == normalize(p.SitusAddress) && (mailZip == "" || situsZip == "" || mailZip == situsZip) {
+		return occOwnerOccupied
+	}
+
+	state := mailingState(p.OwnerCityState)
+	if state != "" && state != homeState {
+		return occOutOfState
+	}
+	if poBoxPattern.MatchString(strings.ToUpper(strings.ReplaceAll(street, ".", ""))) {
+		return occPOBox
+	}
+	if mailZip != "" {
+		for _, prefix := range localZipPrefixes {
+			if strings.HasPrefix(mailZip, prefix) {
+				return occLocalAbsentee
+			}
+		}
+		return occInState
+	}
+	// No zip: fall back to the mailing city when the parcel has one.
+	if p.City != "" && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(p.OwnerCityState)), strings.ToUpper(strings.TrimSpace(p.City))) {
+		return occLocalAbsentee
+	}
+	return occInState
+}
+
+// zip5 returns the first five digits of a zip or zip+4, or "" if it is malformed.
+func zip5(zip string) string {
+	zip = strings.TrimSpace(zip)
+	if len(zip) < 5 {
+		return ""
+	}
+	for _, r := range zip[:5] {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return zip[:5]
+}
+
+// parseOccupancies parses a comma-separated occ= value such as "outofstate,pobox".
+func parseOccupancies(val string) ([]occupancy, error) {
+	var classes []occupancy
+	for _, word := range strings.Split(val, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		o, ok := occupancyAliases[word]
+		if !ok {
+			return nil, fmt.Errorf("occ=: unknown class %q (use occupied, local, instate, outofstate or pobox)", word)
+		}
+		classes = append(classes, o)
+	}
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("occ= expects at least one class")
+	}
+	return classes, nil
+}
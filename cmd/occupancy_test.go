@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestClassifyOccupancy(t *testing.T) {
+	parcel := func(owner, cityState, ownerZip, situsZip string) Property {
+		return Property{SitusAddress: "123 N MAIN ST", SitusZip: situsZip, City: "FORT WORTH",
+			OwnerAddress: owner, OwnerCityState: cityState, OwnerZip: ownerZip}
+	}
+	tests := []struct {
+		name string
+		p    Property
+		want occupancy
+	}{
+		{"no mailing address", parcel("", "", "", "76107"), occUnknown},
+		{"same address", parcel("123 North Main Street", "FORT WORTH, TX", "76107-1234", "76107"), occOwnerOccupied},
+		{"same address, blank situs zip", parcel("123 N MAIN ST", "FORT WORTH, TX", "76107", ""), occOwnerOccupied},
+		{"same street, other zip", parcel("123 N MAIN ST", "ARLINGTON, TX", "76010", "76107"), occLocalAbsentee},
+		{"elsewhere in the county", parcel("9 ELM ST", "FORT WORTH TX", "76104", "76107"), occLocalAbsentee},
+		{"elsewhere in Texas", parcel("9 ELM ST", "AUSTIN, TX", "78701", "76107"), occInState},
+		{"spelled-out state", parcel("9 ELM ST", "HOUSTON TEXAS", "77002", "76107"), occInState},
+		{"out of state", parcel("9 ELM ST", "DENVER, CO", "80202", "76107"), occOutOfState},
+		{"out-of-state box", parcel("PO BOX 12", "DENVER, CO", "80202", "76107"), occOutOfState},
+		{"PO BOX", parcel("PO BOX 123", "FORT WORTH, TX", "76101", "76107"), occPOBox},
+		{"P O BOX", parcel("P O BOX 123", "FORT WORTH, TX", "76101", "76107"), occPOBox},
+		{"P.O. Box", parcel("P.O. Box 123", "FORT WORTH, TX", "76101", ""), occPOBox},
+		{"POST OFFICE BOX", parcel("POST OFFICE BOX 9", "AUSTIN, TX", "78701", "76107"), occPOBox},
+		{"no zip, same city", parcel("9 ELM ST", "FORT WORTH, TX", "", "76107"), occLocalAbsentee},
+		{"no zip, other city", parcel("9 ELM ST", "AUSTIN, TX", "", "76107"), occInState},
+	}
+	for _, tt := range tests {
+		if got := classifyOccupancy(tt.p); got != tt.want {
+			t.Errorf("%s: classifyOccupancy = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ---------------- Owner search & portfolio view ----------------
+
+// minOwnerTokenScore is how closely each query word must match a word of the owner
+// name (1 = exact) for the owner to be considered a hit.
+const minOwnerTokenScore = 0.8
+
+// maxMailingExpansion caps how many extra parcels a single mailing address may pull
+// into a portfolio. Lenders, tax agents and management companies receive mail for
+// thousands of unrelated parcels and would otherwise swamp the result.
+const maxMailingExpansion = 250
+
+// ownerNoise are words that carry no identifying value when comparing owner names.
+var ownerNoise = map[string]bool{
+	"THE": true, "ETAL": true, "ET": true, "AL": true, "ETUX": true, "ETVIR": true, "&": true, "AND": true,
+}
+
+// ownerParcel is one parcel in an owner portfolio together with how it was found.
+type ownerParcel struct {
+	Property
+	Value      float64
+	Acres      float64
+	ViaMailing bool   // included because it shares a mailing address, not the name
+	MailingKey string // see mailingKey
+}
+
+// mailingGroup collects the parcels that share one owner mailing address.
+type mailingGroup struct {
+	Address string // as printed on the first parcel in the group
+	Owners  []string
+	Parcels []ownerParcel
+	Value   float64
+	Acres   float64
+}
+
+// ownerTokens upper-cases the name, strips punctuation and drops noise words.
+func ownerTokens(name string) []string {
+	name = strings.ToUpper(name)
+	name = strings.NewReplacer(",", " ", ".", " ", "/", " ", "-", " ").Replace(name)
+	var tokens []string
+	for _, t := range strings.Fields(name) {
+		if !ownerNoise[t] {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// ownerNameScore returns how well every query token is matched by some owner token,
+// averaged over the query. Word order does not matter, so "JOHN SMITH" finds
+// "SMITH JOHN". A query word that is a prefix of an owner word counts as exact.
+func ownerNameScore(query, owner []string) float64 {
+	if len(query) == 0 || len(owner) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, o := range owner {
+			score := stringSimilarity(q, o)
+			if len(q) >= 3 && strings.HasPrefix(o, q) {
+				score = 1
+			}
+			best = max(best, score)
+		}
+		if best < minOwnerTokenScore {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// mailingKey normalizes an owner's mailing address so the same mailbox matches
+// regardless of punctuation or street-type spelling. It returns "" when the
+// mailing address is blank.
+func mailingKey(p Property) string {
+	street := normalize(p.OwnerAddress)
+	if street == "" {
+		return ""
+	}
+	zip := strings.TrimSpace(p.OwnerZip)
+	if len(zip) > 5 {
+		zip = zip[:5]
+	}
+	return street + " " + zip
+}
+
+// findOwnerPortfolio fuzzy-matches name against every owner in props, then adds the
+// other parcels mailed to the same addresses so portfolios held under several
+// entity names surface together. Groups are ordered by total value.
+func findOwnerPortfolio(name string, props map[string]Property) []mailingGroup {
+	query := ownerTokens(name)
+	if len(query) == 0 {
+		return nil
+	}
+
+	// Score each distinct owner name once and index parcels by mailing address.
+	nameScores := make(map[string]float64)
+	byMailing := make(map[string][]Property)
+	for _, p := range props {
+		if _, ok := nameScores[p.OwnerName]; !ok {
+			nameScores[p.OwnerName] = ownerNameScore(query, ownerTokens(p.OwnerName))
+		}
+		if key := mailingKey(p); key != "" {
+			byMailing[key] = append(byMailing[key], p)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var matched []ownerParcel
+	mailings := make(map[string]bool)
+	for _, p := range props {
+		if nameScores[p.OwnerName] == 0 {
+			continue
+		}
+		seen[p.AccountNum] = true
+		matched = append(matched, newOwnerParcel(p, false))
+		if key := mailingKey(p); key != "" {
+			mailings[key] = true
+		}
+	}
+	for key := range mailings {
+		others := byMailing[key]
+		if len(others) > maxMailingExpansion {
+			fmt.Printf("(skipping %d parcels mailed to %s – likely an agent or lender address)\n", len(others), key)
+			continue
+		}
+		for _, p := range others {
+			if !seen[p.AccountNum] {
+				seen[p.AccountNum] = true
+				matched = append(matched, newOwnerParcel(p, true))
+			}
+		}
+	}
+
+	groupsByKey := make(map[string]*mailingGroup)
+	for _, op := range matched {
+		g, ok := groupsByKey[op.MailingKey]
+		if !ok {
+			g = &mailingGroup{Address: buildOwnerAddress(op.Property)}
+			if op.MailingKey == "" {
+				g.Address = "(no mailing address)"
+			}
+			groupsByKey[op.MailingKey] = g
+		}
+		g.Parcels = append(g.Parcels, op)
+		g.Value += op.Value
+		g.Acres += op.Acres
+	}
+
+	var groups []mailingGroup
+	for _, g := range groupsByKey {
+		owners := make(map[string]bool)
+		for _, op := range g.Parcels {
+			if !owners[op.OwnerName] {
+				owners[op.OwnerName] = true
+				g.Owners = append(g.Owners, op.OwnerName)
+			}
+		}
+		sort.Strings(g.Owners)
+		sort.Slice(g.Parcels, func(i, j int) bool { return g.Parcels[i].Value > g.Parcels[j].Value })
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Value > groups[j].Value })
+	return groups
+}
+
+func newOwnerParcel(p Property, viaMailing bool) ownerParcel {
+	val, _ := parseDollar(p.TotalValue)
+	acres, _ := parseDollar(p.LandAcres)
+	return ownerParcel{
+		Property:   p,
+		Value:      val,
+		Acres:      acres,
+		ViaMailing: viaMailing,
+		MailingKey: mailingKey(p),
+	}
+}
+
+// showOwnerPortfolio prints every parcel held by owners matching name, grouped by
+// mailing address with per-group and overall totals, then opens the selection list.
+func showOwnerPortfolio(name string, hist *history) {
+	groups := findOwnerPortfolio(name, hist.current().ByAcct)
+	if len(groups) == 0 {
+		fmt.Printf("No owners found matching %q\n", name)
+		return
+	}
+
+	var count int
+	var value, acres float64
+	for _, g := range groups {
+		count += len(g.Parcels)
+		value += g.Value
+		acres += g.Acres
+	}
+	fmt.Printf("\nPortfolio for %q: %d parcels | Total value $%.0f | %.2f acres | %d mailing addresses\n", name, count, value, acres, len(groups))
+
+	var lines []string
+	var queries []string
+	for _, g := range groups {
+		fmt.Printf("\nMailing: %s\n  Owners: %s\n  %d parcels | $%.0f | %.2f acres\n", g.Address, strings.Join(g.Owners, "; "), len(g.Parcels), g.Value, g.Acres)
+		for _, op := range g.Parcels {
+			via := ""
+			if op.ViaMailing {
+				via = " (via mailing address)"
+			}
+			line := fmt.Sprintf("%-40s | %-30s | $%10.0f | %6.2f ac%s", op.SitusAddress, op.OwnerName, op.Value, op.Acres, via)
+			lines = append(lines, line)
+			queries = append(queries, "acct="+op.AccountNum)
+			fmt.Println("  " + line)
+		}
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------- Owner-type classification ----------------
+
+// ownerType is the kind of entity holding title, inferred from the owner name.
+type ownerType int
+
+const (
+	ownerIndividual ownerType = iota
+	ownerCompany              // LLC, corporation, partnership
+	ownerTrust
+	ownerEstate // estate of a deceased owner or heirs
+	ownerBank   // banks, mortgage companies and GSEs, usually REO
+	ownerGovernment
+)
+
+// ownerTypeNames are the labels shown in the detail view, in ownerType order.
+var ownerTypeNames = []string{"Individual", "LLC/Corp", "Trust", "Estate/Heirs", "Bank", "Government"}
+
+func (t ownerType) String() string {
+	if int(t) < len(ownerTypeNames) {
+		return ownerTypeNames[t]
+	}
+	return "Unknown"
+}
+
+// ownerTypeAliases maps the words accepted by the owntype= filter onto types.
+var ownerTypeAliases = map[string]ownerType{
+	"individual": ownerIndividual, "person": ownerIndividual,
+	"llc": ownerCompany, "corp": ownerCompany,
+	"company": ownerCompany, "business": ownerCompany,
+	"trust":  ownerTrust,
+	"estate": ownerEstate, "heirs": ownerEstate,
+	"bank": ownerBank, "reo": ownerBank,
+	"government": ownerGovernment, "gov": ownerGovernment,
+	"public": ownerGovernment,
+}
+
+// ownerTypePatterns are matched against the owner name as whole words or phrases, in
+// this order; the first type with a matching pattern wins. Government and banks come
+// first so "US BANK NATIONAL TRUST CO" is a bank rather than a trust, and companies
+// come before estates and trusts so "HERITAGE ESTATES LLC" is a company. "CO" is not
+// among the patterns since "CO-TRUSTEE" and "CO OWNER" are common; classifyOwner only
+// takes it as a company when it ends the name ("HEB GROCERY CO", "SMITH & CO").
+var ownerTypePatterns = []struct {
+	Type     ownerType
+	Patterns []string
+}{
+	{ownerGovernment, []string{
+		"CITY OF", "COUNTY OF", "TARRANT COUNTY", "STATE OF TEXAS", "UNITED STATES", "USA",
+		"ISD", "INDEPENDENT SCHOOL", "HOUSING AUTHORITY", "TXDOT", "WATER DISTRICT",
+		"REGIONAL WATER", "MUNICIPAL UTILITY",
+	}},
+	{ownerBank, []string{
+		"BANK", "MORTGAGE", "FEDERAL NATIONAL", "FEDERAL HOME LOAN", "FANNIE MAE",
+		"FREDDIE MAC", "SECRETARY OF HOUSING", "CREDIT UNION", "SAVINGS", "LENDING",
+	}},
+	{ownerCompany, []string{
+		"LLC", "L L C", "INC", "CORP", "CORPORATION", "COMPANY", "LP", "L P",
+		"LTD", "LLP", "PARTNERS", "PARTNERSHIP", "HOLDINGS", "PROPERTIES", "INVESTMENTS",
+		"VENTURES", "GROUP", "REALTY", "ENTERPRISES", "DEVELOPMENT",
+	}},
+	{ownerEstate, []string{"ESTATE", "EST", "HEIRS", "HEIR", "DECEASED", "DECD"}},
+	{ownerTrust, []string{"TRUST", "TRUSTEE", "TRUSTEES", "TR", "TRS", "REVOCABLE"}},
+}
+
+// classifyOwner infers the owner type from an owner name. Names matching no pattern
+// are treated as individuals.
+func classifyOwner(name string) ownerType {
+	tokens := ownerTokens(name)
+	if len(tokens) == 0 {
+		return ownerIndividual
+	}
+	// "REAL ESTATE" names a business, not a probate estate.
+	padded := " " + strings.ReplaceAll(strings.Join(tokens, " "), "REAL ESTATE", "REALTY") + " "
+	for _, group := range ownerTypePatterns {
+		for _, pat := range group.Patterns {
+			if strings.Contains(padded, " "+pat+" ") {
+				return group.Type
+			}
+		}
+		if group.Type == ownerCompany && tokens[len(tokens)-1] == "CO" {
+			return ownerCompany
+		}
+	}
+	return ownerIndividual
+}
+
+// parseOwnerTypes parses a comma-separated owntype= value such as "llc,trust".
+func parseOwnerTypes(val string) ([]ownerType, error) {
+	var types []ownerType
+	for _, word := range strings.Split(val, ",") {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		t, ok := ownerTypeAliases[word]
+		if !ok {
+			return nil, fmt.Errorf("owntype=: unknown owner type %q (use individual, llc, trust, estate, bank or government)", word)
+		}
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("owntype= expects at least one owner type")
+	}
+	return types, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestClassifyOwner(t *testing.T) {
+	tests := []struct {
+		name string
+		want ownerType
+	}{
+		{"SMITH JOHN", ownerIndividual},
+		{"GARCIA MARIA ETUX JOSE", ownerIndividual},
+		{"SMITH JOHN TR", ownerTrust},
+		{"JONES FAMILY REVOCABLE TRUST", ownerTrust},
+		{"WILLIAMS MARY CO-TRUSTEE", ownerTrust},
+		{"BROWN ROBERT CO TRUSTEE", ownerTrust},
+		{"DAVIS JAMES EST", ownerEstate},
+		{"MILLER HELEN ESTATE OF", ownerEstate},
+		{"WILSON HEIRS", ownerEstate},
+		{"OPENDOOR PROPERTY J LLC", ownerCompany},
+		{"HERITAGE ESTATES LLC", ownerCompany},
+		{"ACME REAL ESTATE INC", ownerCompany},
+		{"PROGRESS RESIDENTIAL BORROWER 6 L L C", ownerCompany},
+		{"HEB GROCERY CO", ownerCompany},
+		{"GARCIA CO", ownerCompany},
+		{"SMITH & CO", ownerCompany},
+		{"JOHNSON LINDA CO TRUSTEES", ownerTrust},
+		{"CO OWNER SMITH JOHN", ownerIndividual},
+		{"WELLS FARGO BANK NA", ownerBank},
+		{"US BANK NATIONAL TRUST CO", ownerBank},
+		{"FEDERAL NATIONAL MORTGAGE ASSN", ownerBank},
+		{"SECRETARY OF HOUSING & URBAN DEV", ownerBank},
+		{"FORT WORTH CITY OF", ownerGovernment},
+		{"FORT WORTH ISD", ownerGovernment},
+		{"", ownerIndividual},
+	}
+	for _, tt := range tests {
+		if got := classifyOwner(tt.name); got != tt.want {
+			t.Errorf("classifyOwner(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
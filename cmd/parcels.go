@@ -0,0 +1,258 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ---------------- Parcel boundaries ----------------
+
+// parcelLayer is the optional parcel-polygon shapefile, data/<parcelLayer>/<parcelLayer>.shp.
+// Without it every parcel is the single point from the supplemental export.
+const parcelLayer = "PARCELS"
+
+// parcelAccountFields are the DBF fields that may hold the TAD account number, in order
+// of preference. DBF field names are truncated to 10 characters.
+var parcelAccountFields = []string{"ACCOUNT_NU", "ACCOUNT", "ACCT_NUM", "ACCT", "TAXPIN"}
+
+// frontageProbeFt is how far past a lot line we look for a neighbouring parcel. Lot lines
+// of adjoining parcels rarely coincide exactly, so the probe has to clear small slivers.
+const frontageProbeFt = 3.0
+
+// zoningShareGrid is the number of sample points along each side of a parcel's bounding
+// box when estimating how much of the lot lies in each zoning district.
+const zoningShareGrid = 40
+
+// parcelShape is one parcel polygon joined to its account.
+type parcelShape struct {
+	Acct string
+	polygon
+}
+
+// parcelShapes holds the loaded parcel polygons; parcelShapeIndex maps an account to its
+// position and parcelIndex is an R-tree over their bounding boxes.
+var (
+	parcelShapes     []parcelShape
+	parcelShapeIndex map[string]int
+	parcelIndex      *rtree
+)
+
+// initParcels loads the parcel layer if one is present. A missing layer is not an error;
+// the tool falls back to parcel points.
+func initParcels() error {
+	path := filepath.Join("data", parcelLayer, parcelLayer+".shp")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	polys, attrs, err := loadPolygonShapefile(path)
+	if err != nil {
+		return fmt.Errorf("load parcel shapefile %s: %w", path, err)
+	}
+	var shapes []parcelShape
+	for i, p := range polys {
+		if acct := parcelAccount(attrs[i]); acct != "" {
+			shapes = append(shapes, parcelShape{Acct: acct, polygon: p})
+		}
+	}
+	if len(shapes) == 0 {
+		return fmt.Errorf("%s: no account number in any of the fields %s", path, strings.Join(parcelAccountFields, ", "))
+	}
+	indexParcelShapes(shapes)
+	return nil
+}
+
+// indexParcelShapes installs shapes as the parcel layer and rebuilds its indexes.
+func indexParcelShapes(shapes []parcelShape) {
+	parcelShapes = shapes
+	parcelShapeIndex = make(map[string]int, len(shapes))
+	boxes := make([]bbox, len(shapes))
+	for i, s := range shapes {
+		parcelShapeIndex[s.Acct] = i
+		boxes[i] = s.bbox
+	}
+	parcelIndex = newRTree(boxes)
+}
+
+// parcelAccount returns the account number from a parcel's attributes. Field names are
+// matched case-insensitively since counties differ.
+func parcelAccount(attrs map[string]string) string {
+	upper := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		upper[strings.ToUpper(k)] = strings.TrimSpace(v)
+	}
+	for _, f := range parcelAccountFields {
+		if v := upper[f]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parcelGeometry returns the polygon of the parcel with the given account, if the parcel
+// layer is loaded and contains it.
+func parcelGeometry(acct string) (parcelShape, bool) {
+	i, ok := parcelShapeIndex[acct]
+	if !ok {
+		return parcelShape{}, false
+	}
+	return parcelShapes[i], true
+}
+
+// parcelAcres returns the parcel's land area in acres: Land_Acres from the appraisal roll,
+// or the area of its polygon when the roll leaves it blank.
+func parcelAcres(p Property) (float64, bool) {
+	if acres, ok := parseNumber(p.LandAcres); ok {
+		return acres, true
+	}
+	if s, ok := parcelGeometry(p.AccountNum); ok {
+		return s.area() / 43560, true
+	}
+	return 0, false
+}
+
+// lotSqFt returns the lot size in square feet from Land_SqFt, falling back to the parcel's
+// acreage when the roll leaves the square footage blank.
+func lotSqFt(p Property) (float64, bool) {
+	if sqft, ok := parseNumber(p.LandSqFt); ok && sqft > 0 {
+		return sqft, true
+	}
+	if acres, ok := parcelAcres(p); ok && acres > 0 {
+		return acres * 43560, true
+	}
+	return 0, false
+}
+
+// index returns the shape's position in parcelShapes, or -1 when it is not part of the
+// loaded layer, so lookups that skip the lot itself skip nothing.
+func (s parcelShape) index() int {
+	if i, ok := parcelShapeIndex[s.Acct]; ok {
+		return i
+	}
+	return -1
+}
+
+// parcelAt returns the index of another parcel, not skip, that contains the point, or -1.
+func parcelAt(lat, lon float64, skip int) int {
+	found := -1
+	parcelIndex.searchPoint(lat, lon, func(i int) {
+		if found < 0 && i != skip && parcelShapes[i].contains(lat, lon) {
+			found = i
+		}
+	})
+	return found
+}
+
+// frontage approximates street frontage in feet: the length of the outer lot lines that
+// do not border another parcel. Without street centerlines an alley or unplatted land
+// behind the lot also counts, so corner and through lots read high.
+func (s parcelShape) frontage() float64 {
+	self := s.index()
+	var total float64
+	for r, ring := range s.Parts {
+		if s.Holes[r] {
+			continue
+		}
+		for i := 0; i+1 < len(ring); i++ {
+			a, b := ring[i], ring[i+1]
+			length := math.Hypot(b[0]-a[0], b[1]-a[1])
+			if length == 0 {
+				continue
+			}
+			if lat, lon := s.probe(a, b, 0.5, frontageProbeFt); parcelAt(lat, lon, self) < 0 {
+				total += length
+			}
+		}
+	}
+	return total
+}
+
+// probe returns the point dist feet off the lot line a→b, a fraction t of the way along
+// it, on the side away from the lot.
+func (s parcelShape) probe(a, b [2]float64, t, dist float64) (lat, lon float64) {
+	length := math.Hypot(b[0]-a[0], b[1]-a[1])
+	onLat, onLon := a[0]+t*(b[0]-a[0]), a[1]+t*(b[1]-a[1])
+	nLat, nLon := -(b[1]-a[1])/length*dist, (b[0]-a[0])/length*dist
+	if s.contains(onLat+nLat, onLon+nLon) {
+		return onLat - nLat, onLon - nLon
+	}
+	return onLat + nLat, onLon + nLon
+}
+
+// neighbours calls fn once with the account of every other parcel whose boundary comes
+// within gapFt of the lot's, measured edge to edge, so a parcel touching only at a corner
+// or sharing a short stretch of one side is found as well.
+func (s parcelShape) neighbours(gapFt float64, fn func(acct string)) {
+	self := s.index()
+	near := bbox{MinLat: s.MinLat - gapFt, MinLon: s.MinLon - gapFt, MaxLat: s.MaxLat + gapFt, MaxLon: s.MaxLon + gapFt}
+	parcelIndex.search(near, func(j int) {
+		if j != self && s.distance(parcelShapes[j].polygon) <= gapFt {
+			fn(parcelShapes[j].Acct)
+		}
+	})
+}
+
+// zoneShare is the fraction of a lot inside one base zoning district.
+type zoneShare struct {
+	Code    string
+	Percent float64
+}
+
+// zoningShares estimates the percentage of the lot in each base zoning district by
+// sampling a grid of points across it. Districts are listed largest share first; the
+// shares add up to less than 100 when part of the lot is outside every district.
+func (s parcelShape) zoningShares() []zoneShare {
+	counts := make(map[string]int)
+	inside := 0
+	dLat := (s.MaxLat - s.MinLat) / zoningShareGrid
+	dLon := (s.MaxLon - s.MinLon) / zoningShareGrid
+	for i := 0; i < zoningShareGrid; i++ {
+		lat := s.MinLat + (float64(i)+0.5)*dLat
+		for j := 0; j < zoningShareGrid; j++ {
+			lon := s.MinLon + (float64(j)+0.5)*dLon
+			if !s.contains(lat, lon) {
+				continue
+			}
+			inside++
+			for _, f := range findZoningFeatures(lat, lon) {
+				if !f.Overlay && f.code() != "" {
+					counts[f.code()]++
+					break
+				}
+			}
+		}
+	}
+	if inside == 0 {
+		return nil
+	}
+	shares := make([]zoneShare, 0, len(counts))
+	for code, n := range counts {
+		shares = append(shares, zoneShare{Code: code, Percent: 100 * float64(n) / float64(inside)})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Percent != shares[j].Percent {
+			return shares[i].Percent > shares[j].Percent
+		}
+		return shares[i].Code < shares[j].Code
+	})
+	return shares
+}
+
+// lines renders the lot geometry as label/value pairs for the property details.
+func (s parcelShape) lines() [][2]string {
+	out := [][2]string{{"Lot (parcel map)", fmt.Sprintf("%s sf, %s ft perimeter, ~%s ft frontage",
+		formatThousands(s.area()), formatThousands(s.perimeter()), formatThousands(s.frontage()))}}
+	if shares := s.zoningShares(); len(shares) > 0 {
+		parts := make([]string, len(shares))
+		for i, z := range shares {
+			parts[i] = fmt.Sprintf("%s %.0f%%", z.Code, z.Percent)
+		}
+		out = append(out, [2]string{"Zoning by area", strings.Join(parts, ", ")})
+	}
+	return out
+}
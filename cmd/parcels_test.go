@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParcelFrontage(t *testing.T) {
+	saved := parcelShapes
+	defer indexParcelShapes(saved)
+
+	// Three 50 ft wide, 100 ft deep lots side by side facing a street to the south.
+	indexParcelShapes([]parcelShape{
+		{Acct: "1", polygon: newPolygon([][][2]float64{square(0, 0, 100, 50, true)})},
+		{Acct: "2", polygon: newPolygon([][][2]float64{square(0, 50, 100, 100, true)})},
+		{Acct: "3", polygon: newPolygon([][][2]float64{square(0, 100, 100, 150, true)})},
+	})
+
+	tests := []struct {
+		acct string
+		want float64
+	}{
+		{"1", 200}, // front, back and the open west side
+		{"2", 100}, // front and back only
+		{"3", 200},
+	}
+	for _, tt := range tests {
+		s, ok := parcelGeometry(tt.acct)
+		if !ok {
+			t.Fatalf("parcel %s not indexed", tt.acct)
+		}
+		if got := s.frontage(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("frontage(%s) = %g, want %g", tt.acct, got, tt.want)
+		}
+	}
+
+	// A lot missing from the layer must still see the first indexed parcel as a neighbour.
+	west := parcelShape{Acct: "9", polygon: newPolygon([][][2]float64{square(0, -50, 100, 0, true)})}
+	if got := west.frontage(); math.Abs(got-200) > 1e-9 {
+		t.Errorf("frontage of an unindexed lot = %g, want 200", got)
+	}
+}
+
+func TestParcelNeighbours(t *testing.T) {
+	saved := parcelShapes
+	defer indexParcelShapes(saved)
+
+	// A 100 ft lot with one lot touching its north-east corner, one whose corner faces
+	// its south-east corner 14 ft away diagonally, and one 40 ft to the west.
+	indexParcelShapes([]parcelShape{
+		{Acct: "1", polygon: newPolygon([][][2]float64{square(0, 0, 100, 100, true)})},
+		{Acct: "2", polygon: newPolygon([][][2]float64{square(100, 100, 200, 200, true)})},
+		{Acct: "3", polygon: newPolygon([][][2]float64{square(-110, 110, -10, 210, true)})},
+		{Acct: "4", polygon: newPolygon([][][2]float64{square(0, -140, 100, -40, true)})},
+	})
+	lot, _ := parcelGeometry("1")
+	tests := []struct {
+		gapFt float64
+		want  string
+	}{
+		{0, "2"},
+		{20, "2,3"},
+		{50, "2,3,4"},
+	}
+	for _, tt := range tests {
+		var got []string
+		lot.neighbours(tt.gapFt, func(acct string) { got = append(got, acct) })
+		sort.Strings(got)
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("neighbours within %g ft = %v, want %s", tt.gapFt, got, tt.want)
+		}
+	}
+}
+
+func TestParcelZoningShares(t *testing.T) {
+	savedZoning := zoningFeatures
+	defer func() {
+		zoningFeatures = savedZoning
+		indexZoningFeatures()
+	}()
+	// The district line runs through the lot a quarter of the way across.
+	zoningFeatures = []zoningFeature{
+		testFeature("A-5", square(0, -100, 100, 25, true)),
+		testFeature("B", square(0, 25, 100, 200, true)),
+	}
+	indexZoningFeatures()
+
+	lot := parcelShape{Acct: "1", polygon: newPolygon([][][2]float64{square(0, 0, 100, 100, true)})}
+	shares := lot.zoningShares()
+	if len(shares) != 2 || shares[0].Code != "B" || shares[0].Percent != 75 || shares[1].Percent != 25 {
+		t.Errorf("zoningShares = %+v, want B 75%%, A-5 25%%", shares)
+	}
+}
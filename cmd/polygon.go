@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	shp "github.com/jonas-p/go-shp"
+)
+
+// ---------------- Polygon layers ----------------
+
+// polygon is a possibly multi-part polygon in the working coordinate system (Texas
+// North Central feet, stored as [lat, lon] = [northing, easting]).
+type polygon struct {
+	Parts [][][2]float64 // each part is a closed ring of [lat, lon] points
+	Holes []bool         // Holes[i] is true when Parts[i] is an inner ring
+	bbox
+}
+
+// newPolygon classifies each ring as an outer ring or a hole and computes the bounding box.
+func newPolygon(parts [][][2]float64) polygon {
+	p := polygon{
+		Parts: parts,
+		Holes: make([]bool, len(parts)),
+		bbox: bbox{
+			MinLat: math.MaxFloat64, MinLon: math.MaxFloat64,
+			MaxLat: -math.MaxFloat64, MaxLon: -math.MaxFloat64,
+		},
+	}
+	for i, ring := range parts {
+		p.Holes[i] = ringIsHole(ring)
+		for _, pt := range ring {
+			p.MinLat = math.Min(p.MinLat, pt[0])
+			p.MaxLat = math.Max(p.MaxLat, pt[0])
+			p.MinLon = math.Min(p.MinLon, pt[1])
+			p.MaxLon = math.Max(p.MaxLon, pt[1])
+		}
+	}
+	return p
+}
+
+// loadPolygonShapefile reads every polygon in the shapefile, reprojected into the working
+// coordinate system, together with its DBF attributes. attrs[i] belongs to polys[i].
+func loadPolygonShapefile(path string) (polys []polygon, attrs []map[string]string, err error) {
+	r, err := shp.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	// Reproject into the working coordinate system when the layer uses another one.
+	src, err := loadProjection(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v; assuming Texas North Central feet\n", path, err)
+		src = txNorthCentral
+	}
+	convert := reprojector(src, txNorthCentral)
+
+	fields := r.Fields()
+	for r.Next() {
+		idx, shape := r.Shape()
+		poly, ok := shape.(*shp.Polygon)
+		if !ok {
+			// Skip non-polygon geometries (shouldn't exist in a polygon layer)
+			continue
+		}
+
+		// Split the flat points slice into parts.
+		numParts := len(poly.Parts)
+		parts := make([][][2]float64, numParts)
+		for partIdx := 0; partIdx < numParts; partIdx++ {
+			start := poly.Parts[partIdx]
+			end := int32(len(poly.Points))
+			if partIdx+1 < numParts {
+				end = poly.Parts[partIdx+1]
+			}
+			ring := make([][2]float64, 0, int(end-start))
+			for i := start; i < end; i++ {
+				y, x := poly.Points[i].Y, poly.Points[i].X
+				if convert != nil {
+					y, x = convert(y, x)
+				}
+				ring = append(ring, [2]float64{y, x}) // lat, lon
+			}
+			parts[partIdx] = ring
+		}
+
+		a := make(map[string]string, len(fields))
+		for i, f := range fields {
+			// Some writers pad text fields with NULs rather than spaces.
+			a[f.String()] = strings.TrimRight(r.ReadAttribute(idx, i), "\x00")
+		}
+
+		polys = append(polys, newPolygon(parts))
+		attrs = append(attrs, a)
+	}
+	return polys, attrs, nil
+}
+
+// contains reports whether the point lies inside the polygon. Each outer ring
+// containing the point counts +1 and each hole -1, so a point in a hole is outside
+// while a point on an island inside a hole is inside again.
+func (p polygon) contains(lat, lon float64) bool {
+	if lat < p.MinLat || lat > p.MaxLat || lon < p.MinLon || lon > p.MaxLon {
+		return false // quick bbox reject
+	}
+	depth := 0
+	for i, ring := range p.Parts {
+		if !pointInPolygon(lat, lon, ring) {
+			continue
+		}
+		if i < len(p.Holes) && p.Holes[i] {
+			depth--
+		} else {
+			depth++
+		}
+	}
+	return depth > 0
+}
+
+// area returns the polygon's area in square working units: outer rings minus holes.
+func (p polygon) area() float64 {
+	var total float64
+	for i, ring := range p.Parts {
+		a := math.Abs(ringSignedArea(ring))
+		if i < len(p.Holes) && p.Holes[i] {
+			a = -a
+		}
+		total += a
+	}
+	return total
+}
+
+// perimeter returns the total length of every ring, holes included.
+func (p polygon) perimeter() float64 {
+	var total float64
+	for _, ring := range p.Parts {
+		for i := 0; i+1 < len(ring); i++ {
+			total += math.Hypot(ring[i+1][0]-ring[i][0], ring[i+1][1]-ring[i][1])
+		}
+	}
+	return total
+}
+
+// distance returns the shortest distance between the boundaries of p and q, or 0 when
+// they touch, cross or one lies inside the other. Lots meeting only at a corner are 0
+// apart; lots whose corners face each other across a gap are the diagonal distance.
+func (p polygon) distance(q polygon) float64 {
+	for _, ring := range q.Parts {
+		if len(ring) > 0 && p.contains(ring[0][0], ring[0][1]) {
+			return 0
+		}
+	}
+	for _, ring := range p.Parts {
+		if len(ring) > 0 && q.contains(ring[0][0], ring[0][1]) {
+			return 0
+		}
+	}
+	best := math.Inf(1)
+	for _, rp := range p.Parts {
+		for i := 0; i+1 < len(rp); i++ {
+			for _, rq := range q.Parts {
+				for j := 0; j+1 < len(rq); j++ {
+					if d := segmentDistance(rp[i], rp[i+1], rq[j], rq[j+1]); d < best {
+						if d == 0 {
+							return 0
+						}
+						best = d
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// segmentDistance is the shortest distance between segments a→b and c→d.
+func segmentDistance(a, b, c, d [2]float64) float64 {
+	cross := func(o, p, q [2]float64) float64 {
+		return (p[0]-o[0])*(q[1]-o[1]) - (p[1]-o[1])*(q[0]-o[0])
+	}
+	if cross(a, b, c)*cross(a, b, d) < 0 && cross(c, d, a)*cross(c, d, b) < 0 {
+		return 0 // the segments cross
+	}
+	return math.Min(
+		math.Min(pointSegmentDistance(a, c, d), pointSegmentDistance(b, c, d)),
+		math.Min(pointSegmentDistance(c, a, b), pointSegmentDistance(d, a, b)),
+	)
+}
+
+// pointSegmentDistance is the distance from pt to the nearest point of segment a→b.
+func pointSegmentDistance(pt, a, b [2]float64) float64 {
+	dLat, dLon := b[0]-a[0], b[1]-a[1]
+	t := 0.0
+	if l2 := dLat*dLat + dLon*dLon; l2 > 0 {
+		t = math.Max(0, math.Min(1, ((pt[0]-a[0])*dLat+(pt[1]-a[1])*dLon)/l2))
+	}
+	return math.Hypot(pt[0]-(a[0]+t*dLat), pt[1]-(a[1]+t*dLon))
+}
+
+// ringSignedArea is the shoelace area of a ring with x east and y north: positive for
+// counter-clockwise rings, negative for clockwise ones.
+func ringSignedArea(ring [][2]float64) float64 {
+	var sum float64
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		x1, y1 := ring[i][1], ring[i][0]
+		x2, y2 := ring[j][1], ring[j][0]
+		sum += x1*y2 - x2*y1
+	}
+	return sum / 2
+}
+
+// ringIsHole classifies a ring by winding order. Shapefiles store outer rings
+// clockwise and holes counter-clockwise when viewed with x east and y north.
+func ringIsHole(ring [][2]float64) bool {
+	return ringSignedArea(ring) > 0
+}
+
+// pointInPolygon implements the ray-casting algorithm for testing whether a
+// point is inside a polygon. The polygon must be closed (first == last) but we
+// don't require that here since shapefile rings are closed.
+func pointInPolygon(lat, lon float64, ring [][2]float64) bool {
+	inside := false
+	j := len(ring) - 1
+	for i := 0; i < len(ring); i++ {
+		yi, xi := ring[i][0], ring[i][1]
+		yj, xj := ring[j][0], ring[j][1]
+		intersect := ((yi > lat) != (yj > lat)) && (lon < (xj-xi)*(lat-yi)/(yj-yi)+xi)
+		if intersect {
+			inside = !inside
+		}
+		j = i
+	}
+	return inside
+}
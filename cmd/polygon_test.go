@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPolygonAreaAndPerimeter(t *testing.T) {
+	// A 100x50 lot with a 10x10 hole.
+	p := newPolygon([][][2]float64{
+		square(0, 0, 100, 50, true),
+		square(20, 20, 30, 30, false),
+	})
+	if got := p.area(); got != 4900 {
+		t.Errorf("area = %g, want 4900", got)
+	}
+	if got := p.perimeter(); got != 340 {
+		t.Errorf("perimeter = %g, want 340", got)
+	}
+	if p.MinLat != 0 || p.MaxLat != 100 || p.MinLon != 0 || p.MaxLon != 50 {
+		t.Errorf("bbox = %+v", p.bbox)
+	}
+}
+
+func TestPolygonDistance(t *testing.T) {
+	lot := newPolygon([][][2]float64{square(0, 0, 100, 100, true)})
+	tests := []struct {
+		name  string
+		other polygon
+		want  float64
+	}{
+		{"shared side", newPolygon([][][2]float64{square(0, 100, 100, 200, true)}), 0},
+		{"corner only", newPolygon([][][2]float64{square(100, 100, 200, 200, true)}), 0},
+		{"across an alley", newPolygon([][][2]float64{square(0, 115, 100, 215, true)}), 15},
+		{"diagonal gap", newPolygon([][][2]float64{square(103, 104, 200, 200, true)}), 5},
+		{"inside", newPolygon([][][2]float64{square(10, 10, 20, 20, true)}), 0},
+	}
+	for _, tt := range tests {
+		if got := lot.distance(tt.other); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: distance = %g, want %g", tt.name, got, tt.want)
+		}
+	}
+}
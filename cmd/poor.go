@@ -3,13 +3,13 @@ package main
 import "strings"
 
 // findPoorConditionInSubdivision returns all properties within the given subdivision
-// whose Condition field equals "Poor" (case-insensitive).
+// whose Condition grade is Poor or worse (Unsound), however TAD spaces or cases it.
 func findPoorConditionInSubdivision(sub string, props map[string]Property) []Property {
 	sub = strings.ToUpper(strings.TrimSpace(sub))
 	var results []Property
 	for _, p := range props {
-		if strings.ToUpper(strings.TrimSpace(p.Subdivision)) == sub &&
-			strings.EqualFold(strings.TrimSpace(p.Condition), "POOR") {
+		if rank, ok := gradeRank(p.Condition); ok && rank <= gradeRanks["POOR"] &&
+			strings.ToUpper(strings.TrimSpace(p.Subdivision)) == sub {
 			results = append(results, p)
 		}
 	}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ---------------- Map projections ----------------
+
+// projection converts between geographic coordinates in decimal degrees and a layer's
+// planar coordinates. Planar coordinates are returned northing first to match the
+// [lat, lon] ordering of zoningFeature rings. Datum shifts are ignored: NAD83 and
+// WGS-84 differ by about a metre, well under the size of a parcel.
+type projection interface {
+	forward(latDeg, lonDeg float64) (northing, easting float64)
+	inverse(northing, easting float64) (latDeg, lonDeg float64)
+}
+
+// ellipsoid is a reference ellipsoid given by its semi-major axis in metres and its
+// eccentricity squared.
+type ellipsoid struct {
+	a, e2 float64
+}
+
+// newEllipsoid builds an ellipsoid from the semi-major axis and inverse flattening
+// found in a WKT SPHEROID. An inverse flattening of 0 denotes a sphere.
+func newEllipsoid(a, invFlattening float64) ellipsoid {
+	if invFlattening == 0 {
+		return ellipsoid{a: a}
+	}
+	f := 1 / invFlattening
+	return ellipsoid{a: a, e2: 2*f - f*f}
+}
+
+var grs80 = newEllipsoid(6378137.0, 298.257222101)
+
+const degToRad = math.Pi / 180
+
+// geographic is a layer stored directly in longitude/latitude degrees.
+type geographic struct{}
+
+func (geographic) forward(latDeg, lonDeg float64) (float64, float64) { return latDeg, lonDeg }
+func (geographic) inverse(lat, lon float64) (float64, float64)       { return lat, lon }
+
+// lambertConformalConic implements EPSG methods 9801 (one standard parallel with a
+// scale factor) and 9802 (two standard parallels).
+type lambertConformalConic struct {
+	ell                      ellipsoid
+	lon0                     float64 // radians
+	falseEasting, falseNorth float64 // metres
+	unit                     float64 // metres per planar unit
+	n, af, rho0              float64 // derived: cone constant, a·F·k0, radius at the origin
+}
+
+// newLambertConformalConic builds an LCC projection from degrees and planar units. When
+// lat1 == lat2 the single-parallel form is used with scale factor k0 at that parallel.
+func newLambertConformalConic(ell ellipsoid, lat0, lon0, lat1, lat2, k0, falseEasting, falseNorthing, unit float64) lambertConformalConic {
+	p := lambertConformalConic{
+		ell:          ell,
+		lon0:         lon0 * degToRad,
+		falseEasting: falseEasting * unit,
+		falseNorth:   falseNorthing * unit,
+		unit:         unit,
+	}
+	phi0, phi1, phi2 := lat0*degToRad, lat1*degToRad, lat2*degToRad
+	m1, t1 := ell.m(phi1), ell.t(phi1)
+	if lat1 == lat2 {
+		p.n = math.Sin(phi1)
+	} else {
+		p.n = (math.Log(m1) - math.Log(ell.m(phi2))) / (math.Log(t1) - math.Log(ell.t(phi2)))
+	}
+	p.af = ell.a * m1 / (p.n * math.Pow(t1, p.n)) * k0
+	p.rho0 = p.af * math.Pow(ell.t(phi0), p.n)
+	return p
+}
+
+// m and t are the auxiliary functions of the EPSG LCC formulas.
+func (e ellipsoid) m(phi float64) float64 {
+	s := math.Sin(phi)
+	return math.Cos(phi) / math.Sqrt(1-e.e2*s*s)
+}
+
+func (e ellipsoid) t(phi float64) float64 {
+	ecc := math.Sqrt(e.e2)
+	s := math.Sin(phi)
+	return math.Tan(math.Pi/4-phi/2) / math.Pow((1-ecc*s)/(1+ecc*s), ecc/2)
+}
+
+func (p lambertConformalConic) forward(latDeg, lonDeg float64) (float64, float64) {
+	rho := p.af * math.Pow(p.ell.t(latDeg*degToRad), p.n)
+	theta := p.n * (lonDeg*degToRad - p.lon0)
+	easting := p.falseEasting + rho*math.Sin(theta)
+	northing := p.falseNorth + p.rho0 - rho*math.Cos(theta)
+	return northing / p.unit, easting / p.unit
+}
+
+func (p lambertConformalConic) inverse(northing, easting float64) (float64, float64) {
+	dx := easting*p.unit - p.falseEasting
+	dy := p.rho0 - (northing*p.unit - p.falseNorth)
+	sign := 1.0
+	if p.n < 0 {
+		sign = -1
+	}
+	rho := sign * math.Hypot(dx, dy)
+	theta := math.Atan2(sign*dx, sign*dy)
+	t := math.Pow(rho/p.af, 1/p.n)
+
+	ecc := math.Sqrt(p.ell.e2)
+	phi := math.Pi/2 - 2*math.Atan(t)
+	for i := 0; i < 15; i++ {
+		s := math.Sin(phi)
+		next := math.Pi/2 - 2*math.Atan(t*math.Pow((1-ecc*s)/(1+ecc*s), ecc/2))
+		if math.Abs(next-phi) < 1e-12 {
+			phi = next
+			break
+		}
+		phi = next
+	}
+	return phi / degToRad, (theta/p.n + p.lon0) / degToRad
+}
+
+// transverseMercator implements EPSG method 9807 with the USGS (Snyder) series, which
+// is accurate to well under a millimetre within a few degrees of the central meridian.
+type transverseMercator struct {
+	ell                      ellipsoid
+	lat0, lon0, k0           float64 // radians, radians, scale
+	falseEasting, falseNorth float64 // metres
+	unit                     float64 // metres per planar unit
+	m0                       float64 // meridional arc to the latitude of origin
+}
+
+func newTransverseMercator(ell ellipsoid, lat0, lon0, k0, falseEasting, falseNorthing, unit float64) transverseMercator {
+	p := transverseMercator{
+		ell:          ell,
+		lat0:         lat0 * degToRad,
+		lon0:         lon0 * degToRad,
+		k0:           k0,
+		falseEasting: falseEasting * unit,
+		falseNorth:   falseNorthing * unit,
+		unit:         unit,
+	}
+	p.m0 = ell.meridianArc(p.lat0)
+	return p
+}
+
+// meridianArc is the distance along the meridian from the equator to latitude phi.
+func (e ellipsoid) meridianArc(phi float64) float64 {
+	e2, e4, e6 := e.e2, e.e2*e.e2, e.e2*e.e2*e.e2
+	return e.a * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+}
+
+func (p transverseMercator) forward(latDeg, lonDeg float64) (float64, float64) {
+	phi := latDeg * degToRad
+	ep2 := p.ell.e2 / (1 - p.ell.e2)
+	sin, cos, tan := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	nu := p.ell.a / math.Sqrt(1-p.ell.e2*sin*sin)
+	t := tan * tan
+	c := ep2 * cos * cos
+	a := (lonDeg*degToRad - p.lon0) * cos
+
+	x := p.k0 * nu * (a + (1-t+c)*math.Pow(a, 3)/6 +
+		(5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120)
+	y := p.k0 * (p.ell.meridianArc(phi) - p.m0 + nu*tan*(a*a/2+
+		(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+
+		(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+	return (p.falseNorth + y) / p.unit, (p.falseEasting + x) / p.unit
+}
+
+func (p transverseMercator) inverse(northing, easting float64) (float64, float64) {
+	e2 := p.ell.e2
+	ep2 := e2 / (1 - e2)
+	x := easting*p.unit - p.falseEasting
+	y := northing*p.unit - p.falseNorth
+
+	mu := (p.m0 + y/p.k0) / (p.ell.a * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+	phi1 := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sin, cos, tan := math.Sin(phi1), math.Cos(phi1), math.Tan(phi1)
+	c1 := ep2 * cos * cos
+	t1 := tan * tan
+	nu1 := p.ell.a / math.Sqrt(1-e2*sin*sin)
+	rho1 := p.ell.a * (1 - e2) / math.Pow(1-e2*sin*sin, 1.5)
+	d := x / (nu1 * p.k0)
+
+	phi := phi1 - (nu1*tan/rho1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*ep2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*ep2-3*c1*c1)*math.Pow(d, 6)/720)
+	lambda := p.lon0 + (d-(1+2*t1+c1)*math.Pow(d, 3)/6+
+		(5-2*c1+28*t1-3*c1*c1+8*ep2+24*t1*t1)*math.Pow(d, 5)/120)/cos
+	return phi / degToRad, lambda / degToRad
+}
+
+// reprojector returns a function converting planar coordinates from one projection to
+// another, or nil when the two agree to within a millimetre across the county and no
+// conversion is needed.
+func reprojector(from, to projection) func(northing, easting float64) (float64, float64) {
+	if sameProjection(from, to) {
+		return nil
+	}
+	return func(northing, easting float64) (float64, float64) {
+		return to.forward(from.inverse(northing, easting))
+	}
+}
+
+// sameProjection compares two projections by projecting a grid of points around
+// Tarrant County with both. Comparing parameters directly would trip over the
+// rounding differences between .prj files written by different tools.
+func sameProjection(a, b projection) bool {
+	for lat := 32.5; lat <= 33.0; lat += 0.25 {
+		for lon := -97.6; lon <= -97.0; lon += 0.3 {
+			an, ae := a.forward(lat, lon)
+			bn, be := b.forward(lat, lon)
+			if math.Abs(an-bn) > 0.001 || math.Abs(ae-be) > 0.001 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ---------------- ESRI WKT (.prj) parsing ----------------
+
+// wktNode is one KEYWORD[...] element: its quoted and numeric values in order, and
+// its nested elements.
+type wktNode struct {
+	Keyword  string
+	Values   []string
+	Children []*wktNode
+}
+
+// child returns the first nested element with the keyword, case-insensitively.
+func (n *wktNode) child(keyword string) *wktNode {
+	for _, c := range n.Children {
+		if strings.EqualFold(c.Keyword, keyword) {
+			return c
+		}
+	}
+	return nil
+}
+
+// number parses the i'th value as a float.
+func (n *wktNode) number(i int) (float64, error) {
+	if n == nil || i >= len(n.Values) {
+		return 0, fmt.Errorf("missing value")
+	}
+	return strconv.ParseFloat(n.Values[i], 64)
+}
+
+// parseWKT parses ESRI-style well-known text such as the contents of a .prj file.
+func parseWKT(s string) (*wktNode, error) {
+	p := &wktParser{s: s}
+	node, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("wkt: unexpected %q after the root element", p.s[p.pos:])
+	}
+	return node, nil
+}
+
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// node parses KEYWORD[value, value, CHILD[...], ...]. Round brackets are accepted too.
+func (p *wktParser) node() (*wktNode, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && (unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos])) || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	n := &wktNode{Keyword: p.s[start:p.pos]}
+	if n.Keyword == "" {
+		return nil, fmt.Errorf("wkt: expected keyword at offset %d", start)
+	}
+	p.skipSpace()
+	if p.pos >= len(p.s) || (p.s[p.pos] != '[' && p.s[p.pos] != '(') {
+		return nil, fmt.Errorf("wkt: expected '[' after %s", n.Keyword)
+	}
+	p.pos++
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, fmt.Errorf("wkt: unterminated %s", n.Keyword)
+		}
+		switch c := p.s[p.pos]; {
+		case c == ']' || c == ')':
+			p.pos++
+			return n, nil
+		case c == ',':
+			p.pos++
+		case c == '"':
+			end := strings.IndexByte(p.s[p.pos+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("wkt: unterminated string in %s", n.Keyword)
+			}
+			n.Values = append(n.Values, p.s[p.pos+1:p.pos+1+end])
+			p.pos += end + 2
+		case c == '-' || c == '+' || c == '.' || unicode.IsDigit(rune(c)):
+			start := p.pos
+			for p.pos < len(p.s) && strings.IndexByte("+-.eE0123456789", p.s[p.pos]) >= 0 {
+				p.pos++
+			}
+			n.Values = append(n.Values, p.s[start:p.pos])
+		default:
+			child, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+	}
+}
+
+// projectionFromWKT builds a projection from a GEOGCS or PROJCS definition. Angular
+// parameters are assumed to be in degrees, as ESRI always writes them.
+func projectionFromWKT(wkt string) (projection, error) {
+	root, err := parseWKT(wkt)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToUpper(root.Keyword) {
+	case "GEOGCS":
+		return geographic{}, nil
+	case "PROJCS":
+	default:
+		return nil, fmt.Errorf("unsupported coordinate system %s", root.Keyword)
+	}
+
+	ell := grs80
+	if sph := root.child("GEOGCS").findDeep("SPHEROID"); sph != nil {
+		a, err1 := sph.number(1)
+		invF, err2 := sph.number(2)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid SPHEROID")
+		}
+		ell = newEllipsoid(a, invF)
+	}
+	unit := 1.0
+	if u := root.child("UNIT"); u != nil {
+		if unit, err = u.number(1); err != nil || unit <= 0 {
+			return nil, fmt.Errorf("invalid UNIT")
+		}
+	}
+	params := make(map[string]float64)
+	for _, c := range root.Children {
+		if strings.EqualFold(c.Keyword, "PARAMETER") && len(c.Values) == 2 {
+			v, err := c.number(1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid PARAMETER %s", c.Values[0])
+			}
+			params[strings.ToLower(c.Values[0])] = v
+		}
+	}
+	param := func(name string, def float64) float64 {
+		if v, ok := params[name]; ok {
+			return v
+		}
+		return def
+	}
+	method := ""
+	if m := root.child("PROJECTION"); m != nil && len(m.Values) > 0 {
+		method = strings.ToLower(m.Values[0])
+	}
+
+	fe, fn := param("false_easting", 0), param("false_northing", 0)
+	lat0 := param("latitude_of_origin", param("latitude_of_center", 0))
+	lon0 := param("central_meridian", param("longitude_of_center", 0))
+	k0 := param("scale_factor", 1)
+	switch method {
+	case "lambert_conformal_conic", "lambert_conformal_conic_2sp", "lambert_conformal_conic_1sp":
+		lat1 := param("standard_parallel_1", lat0)
+		lat2 := param("standard_parallel_2", lat1)
+		return newLambertConformalConic(ell, lat0, lon0, lat1, lat2, k0, fe, fn, unit), nil
+	case "transverse_mercator", "gauss_kruger":
+		return newTransverseMercator(ell, lat0, lon0, k0, fe, fn, unit), nil
+	}
+	return nil, fmt.Errorf("unsupported projection %q", method)
+}
+
+// findDeep returns the first element with the keyword at any depth below n.
+func (n *wktNode) findDeep(keyword string) *wktNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if strings.EqualFold(c.Keyword, keyword) {
+			return c
+		}
+		if found := c.findDeep(keyword); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// loadProjection reads the .prj next to a shapefile.
+func loadProjection(shpPath string) (projection, error) {
+	b, err := os.ReadFile(strings.TrimSuffix(shpPath, ".shp") + ".prj")
+	if err != nil {
+		return nil, err
+	}
+	return projectionFromWKT(strings.TrimSpace(string(b)))
+}
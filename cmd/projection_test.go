@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+const txNorthCentralPRJ = `PROJCS["NAD_1983_StatePlane_Texas_North_Central_FIPS_4202_Feet",GEOGCS["GCS_North_American_1983",DATUM["D_North_American_1983",SPHEROID["GRS_1980",6378137.0,298.257222101]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Lambert_Conformal_Conic"],PARAMETER["False_Easting",1968500.0],PARAMETER["False_Northing",6561666.666666666],PARAMETER["Central_Meridian",-98.5],PARAMETER["Standard_Parallel_1",32.13333333333333],PARAMETER["Standard_Parallel_2",33.96666666666667],PARAMETER["Latitude_Of_Origin",31.66666666666667],UNIT["Foot_US",0.3048006096012192]]`
+
+const utm14NPRJ = `PROJCS["WGS_1984_UTM_Zone_14N",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Transverse_Mercator"],PARAMETER["False_Easting",500000.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",-99.0],PARAMETER["Scale_Factor",0.9996],PARAMETER["Latitude_Of_Origin",0.0],UNIT["Meter",1.0]]`
+
+const wgs84PRJ = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+func TestLambertConformalConicEPSGExample(t *testing.T) {
+	// EPSG Guidance Note 7-2, method 9802: NAD27 / Texas South Central.
+	clarke1866 := newEllipsoid(6378206.4, 294.9786982)
+	usFt := 0.3048006096012192
+	p := newLambertConformalConic(clarke1866, 27+50.0/60, -99, 28+23.0/60, 30+17.0/60, 1, 2000000, 0, usFt)
+
+	n, e := p.forward(28.5, -96)
+	if math.Abs(e-2963503.91) > 0.05 || math.Abs(n-254759.80) > 0.05 {
+		t.Errorf("forward = (N %.2f, E %.2f), want (N 254759.80, E 2963503.91)", n, e)
+	}
+	lat, lon := p.inverse(254759.80, 2963503.91)
+	if math.Abs(lat-28.5) > 1e-7 || math.Abs(lon+96) > 1e-7 {
+		t.Errorf("inverse = (%.9f, %.9f), want (28.5, -96)", lat, lon)
+	}
+}
+
+func TestTransverseMercatorEPSGExample(t *testing.T) {
+	// EPSG Guidance Note 7-2, method 9807: OSGB 1936 / British National Grid.
+	airy := newEllipsoid(6377563.396, 299.3249646)
+	p := newTransverseMercator(airy, 49, -2, 0.9996012717, 400000, -100000, 1)
+
+	n, e := p.forward(50.5, 0.5)
+	if math.Abs(e-577274.99) > 0.05 || math.Abs(n-69740.49) > 0.05 {
+		t.Errorf("forward = (N %.2f, E %.2f), want (N 69740.49, E 577274.99)", n, e)
+	}
+	lat, lon := p.inverse(69740.49, 577274.99)
+	if math.Abs(lat-50.5) > 1e-7 || math.Abs(lon-0.5) > 1e-7 {
+		t.Errorf("inverse = (%.9f, %.9f), want (50.5, 0.5)", lat, lon)
+	}
+}
+
+func TestProjectionFromWKT(t *testing.T) {
+	tests := []struct {
+		name string
+		wkt  string
+	}{
+		{"state plane", txNorthCentralPRJ},
+		{"utm", utm14NPRJ},
+		{"geographic", wgs84PRJ},
+	}
+	for _, tt := range tests {
+		p, err := projectionFromWKT(tt.wkt)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		// Round-trip a point in downtown Fort Worth.
+		lat, lon := p.inverse(p.forward(32.7555, -97.3308))
+		if math.Abs(lat-32.7555) > 1e-8 || math.Abs(lon+97.3308) > 1e-8 {
+			t.Errorf("%s: round trip = (%.10f, %.10f)", tt.name, lat, lon)
+		}
+	}
+
+	if _, err := projectionFromWKT(`PROJCS["x",GEOGCS["y"],PROJECTION["Albers"]]`); err == nil {
+		t.Error("unsupported projection accepted")
+	}
+	if _, err := projectionFromWKT(`PROJCS["x",`); err == nil {
+		t.Error("truncated WKT accepted")
+	}
+}
+
+func TestReprojectorIntoWorkingCRS(t *testing.T) {
+	stateplane, _ := projectionFromWKT(txNorthCentralPRJ)
+	if reprojector(stateplane, txNorthCentral) != nil {
+		t.Error("layer already in the working CRS should not be reprojected")
+	}
+
+	wantN, wantE := wgs84ToTxNC(32.7555, -97.3308)
+	for _, wkt := range []string{utm14NPRJ, wgs84PRJ} {
+		src, _ := projectionFromWKT(wkt)
+		convert := reprojector(src, txNorthCentral)
+		if convert == nil {
+			t.Fatalf("%s: expected a reprojection", wkt[:20])
+		}
+		n, e := convert(src.forward(32.7555, -97.3308))
+		if math.Abs(n-wantN) > 0.01 || math.Abs(e-wantE) > 0.01 {
+			t.Errorf("%s: reprojected to (%.3f, %.3f), want (%.3f, %.3f)", wkt[:20], n, e, wantN, wantE)
+		}
+	}
+}
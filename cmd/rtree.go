@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ---------------- STR-packed R-tree ----------------
+
+// rtreeNodeSize is the fan-out of every node. Sixteen keeps the tree shallow (four
+// levels for a county of parcels) while each node's boxes still fit in a few cache lines.
+const rtreeNodeSize = 16
+
+// bbox is an axis-aligned bounding box in whatever planar units the layer uses. The
+// field names follow zoningFeature: Lat is the north/y axis and Lon the east/x axis.
+type bbox struct {
+	MinLat, MinLon, MaxLat, MaxLon float64
+}
+
+func (b bbox) intersects(o bbox) bool {
+	return b.MinLat <= o.MaxLat && o.MinLat <= b.MaxLat && b.MinLon <= o.MaxLon && o.MinLon <= b.MaxLon
+}
+
+func (b bbox) union(o bbox) bbox {
+	return bbox{
+		MinLat: math.Min(b.MinLat, o.MinLat),
+		MinLon: math.Min(b.MinLon, o.MinLon),
+		MaxLat: math.Max(b.MaxLat, o.MaxLat),
+		MaxLon: math.Max(b.MaxLon, o.MaxLon),
+	}
+}
+
+// rtreeNode is either an entry for one indexed box (children == nil) or an inner node
+// whose box covers its children.
+type rtreeNode struct {
+	box      bbox
+	item     int // index of the indexed box; only meaningful when children == nil
+	children []*rtreeNode
+}
+
+// rtree is a static R-tree bulk-loaded with the Sort-Tile-Recursive algorithm. It is
+// built once from a slice of boxes and answers which of those boxes intersect a query.
+type rtree struct {
+	root *rtreeNode
+}
+
+// newRTree packs boxes into a tree. Search results are indexes into boxes.
+func newRTree(boxes []bbox) *rtree {
+	if len(boxes) == 0 {
+		return &rtree{}
+	}
+	level := make([]*rtreeNode, len(boxes))
+	for i, b := range boxes {
+		level[i] = &rtreeNode{box: b, item: i}
+	}
+	for {
+		level = strPack(level)
+		if len(level) == 1 {
+			return &rtree{root: level[0]}
+		}
+	}
+}
+
+// strPack groups nodes into parents of up to rtreeNodeSize: nodes are sorted by x
+// into vertical slices, then each slice is sorted by y and cut into runs.
+func strPack(nodes []*rtreeNode) []*rtreeNode {
+	centerLat := func(n *rtreeNode) float64 { return (n.box.MinLat + n.box.MaxLat) / 2 }
+	centerLon := func(n *rtreeNode) float64 { return (n.box.MinLon + n.box.MaxLon) / 2 }
+
+	parentCount := (len(nodes) + rtreeNodeSize - 1) / rtreeNodeSize
+	sliceCount := int(math.Ceil(math.Sqrt(float64(parentCount))))
+	sliceSize := sliceCount * rtreeNodeSize
+
+	sort.Slice(nodes, func(i, j int) bool { return centerLon(nodes[i]) < centerLon(nodes[j]) })
+	var parents []*rtreeNode
+	for start := 0; start < len(nodes); start += sliceSize {
+		slice := nodes[start:min(start+sliceSize, len(nodes))]
+		sort.Slice(slice, func(i, j int) bool { return centerLat(slice[i]) < centerLat(slice[j]) })
+		for i := 0; i < len(slice); i += rtreeNodeSize {
+			run := slice[i:min(i+rtreeNodeSize, len(slice))]
+			parent := &rtreeNode{box: run[0].box, children: append([]*rtreeNode(nil), run...)}
+			for _, n := range run[1:] {
+				parent.box = parent.box.union(n.box)
+			}
+			parents = append(parents, parent)
+		}
+	}
+	return parents
+}
+
+// search calls fn with the index of every box intersecting q.
+func (t *rtree) search(q bbox, fn func(i int)) {
+	if t == nil || t.root == nil {
+		return
+	}
+	stack := []*rtreeNode{t.root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !n.box.intersects(q) {
+			continue
+		}
+		if n.children == nil {
+			fn(n.item)
+			continue
+		}
+		stack = append(stack, n.children...)
+	}
+}
+
+// searchPoint calls fn with the index of every box containing the point.
+func (t *rtree) searchPoint(lat, lon float64, fn func(i int)) {
+	t.search(bbox{MinLat: lat, MinLon: lon, MaxLat: lat, MaxLon: lon}, fn)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestRTreeMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	boxes := make([]bbox, 1000)
+	for i := range boxes {
+		lat, lon := rng.Float64()*100, rng.Float64()*100
+		boxes[i] = bbox{MinLat: lat, MinLon: lon, MaxLat: lat + rng.Float64()*5, MaxLon: lon + rng.Float64()*5}
+	}
+	tree := newRTree(boxes)
+
+	for q := 0; q < 200; q++ {
+		lat, lon := rng.Float64()*100, rng.Float64()*100
+		query := bbox{MinLat: lat, MinLon: lon, MaxLat: lat + rng.Float64()*3, MaxLon: lon + rng.Float64()*3}
+
+		var got []int
+		tree.search(query, func(i int) { got = append(got, i) })
+		sort.Ints(got)
+
+		var want []int
+		for i, b := range boxes {
+			if b.intersects(query) {
+				want = append(want, i)
+			}
+		}
+		if len(got) != len(want) {
+			t.Fatalf("query %v: got %d hits, want %d", query, len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("query %v: got %v, want %v", query, got, want)
+			}
+		}
+	}
+}
+
+func TestRTreeEmpty(t *testing.T) {
+	newRTree(nil).searchPoint(0, 0, func(int) { t.Error("empty tree returned a hit") })
+	var nilTree *rtree
+	nilTree.searchPoint(0, 0, func(int) { t.Error("nil tree returned a hit") })
+}
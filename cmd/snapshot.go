@@ -0,0 +1,144 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ---------------- Dataset snapshot cache ----------------
+
+// snapshotFile holds the parsed datasets in gob form so later launches can skip
+// re-reading the pipe-delimited exports.
+var snapshotFile = filepath.Join("data", "datasets.snapshot")
+
+// snapshotVersion must be bumped whenever Property or datasetSnapshot change shape, or
+// the way records are parsed and merged changes, so that old snapshots are discarded
+// instead of decoded into the wrong fields or served with stale values.
+const snapshotVersion = 7
+
+// sourceStamp fingerprints one source file. A snapshot is only reused when every
+// stamp still matches the file on disk.
+type sourceStamp struct {
+	Path    string
+	Size    int64
+	ModTime int64  // Unix nanoseconds
+	Hash    string // hex SHA-256 of the file contents
+}
+
+// datasetSnapshot is the on-disk cache: every loaded year's merged records and
+// address index, keyed by tax year.
+type datasetSnapshot struct {
+	Version int
+	Sources []sourceStamp
+	Years   map[int]*yearData
+}
+
+// stampSources stats and hashes each of the given files.
+func stampSources(paths []string) ([]sourceStamp, error) {
+	stamps := make([]sourceStamp, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashFile(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, sourceStamp{
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+			Hash:    hash,
+		})
+	}
+	return stamps, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readSnapshot loads the snapshot at path if it exists and was built from exactly the
+// given sources. Any read or decode failure is treated as a cache miss.
+func readSnapshot(path string, stamps []sourceStamp) (*datasetSnapshot, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, false
+	}
+	defer zr.Close()
+
+	var snap datasetSnapshot
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, false
+	}
+	if snap.Version != snapshotVersion || !sameStamps(snap.Sources, stamps) {
+		return nil, false
+	}
+	return &snap, true
+}
+
+func sameStamps(a, b []sourceStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeSnapshot encodes snap to a temporary file and renames it into place so a
+// crash mid-write never leaves a truncated snapshot behind.
+func writeSnapshot(path string, snap *datasetSnapshot) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	zw, err := gzip.NewWriterLevel(tmp, gzip.BestSpeed)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gob.NewEncoder(zw).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
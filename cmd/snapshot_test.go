@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotInvalidation(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "PropertyData_2025.txt")
+	snapPath := filepath.Join(dir, "datasets.snapshot")
+	mtime := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	setSource := func(content string, mod time.Time) []sourceStamp {
+		t.Helper()
+		if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(src, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+		stamps, err := stampSources([]string{src})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stamps
+	}
+
+	stamps := setSource("Account_Num|Situs_Address\n1|1 MAIN ST\n", mtime)
+	if err := writeSnapshot(snapPath, &datasetSnapshot{Version: snapshotVersion, Sources: stamps}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readSnapshot(snapPath, stamps); !ok {
+		t.Fatal("snapshot not reused for unchanged sources")
+	}
+
+	tests := []struct {
+		name   string
+		stamps []sourceStamp
+	}{
+		{"size changed", setSource("Account_Num|Situs_Address\n1|1 MAIN ST\n2|2 MAIN ST\n", mtime)},
+		{"mtime changed", setSource("Account_Num|Situs_Address\n1|1 MAIN ST\n", mtime.Add(time.Hour))},
+		{"content changed", setSource("Account_Num|Situs_Address\n1|9 MAIN ST\n", mtime)},
+	}
+	for _, tt := range tests {
+		if _, ok := readSnapshot(snapPath, tt.stamps); ok {
+			t.Errorf("%s: stale snapshot reused", tt.name)
+		}
+	}
+
+	if err := writeSnapshot(snapPath, &datasetSnapshot{Version: snapshotVersion - 1, Sources: stamps}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readSnapshot(snapPath, stamps); ok {
+		t.Error("snapshot from an older snapshotVersion reused")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ---------------- Spatial index ----------------
+
+// spatialCellMiles is the edge length of one grid cell. Most neighbor searches use
+// radii between 0.1 and a few miles, so quarter-mile cells keep each query to a
+// handful of cells without making the grid sparse.
+const spatialCellMiles = 0.25
+
+// milesPerDegreeLat is the (near-constant) length of one degree of latitude.
+const milesPerDegreeLat = 69.0
+
+// spatialPoint is one parcel's parsed coordinates.
+type spatialPoint struct {
+	Acct string
+	Lat  float64
+	Lon  float64
+}
+
+// spatialHit is a point returned from a query together with its distance.
+type spatialHit struct {
+	spatialPoint
+	Miles float64
+}
+
+// gridIndex buckets parcels into a fixed lat/lon grid so radius and k-nearest
+// queries only inspect nearby cells instead of the whole county.
+type gridIndex struct {
+	latStep float64 // cell height in degrees
+	lonStep float64 // cell width in degrees
+	cells   map[[2]int][]spatialPoint
+	byAcct  map[string]spatialPoint
+}
+
+// newGridIndex indexes every parcel in props that has parseable coordinates.
+func newGridIndex(props map[string]Property) *gridIndex {
+	g := &gridIndex{
+		cells:  make(map[[2]int][]spatialPoint),
+		byAcct: make(map[string]spatialPoint, len(props)),
+	}
+	// Size longitude cells for the mean latitude so cells are roughly square.
+	var sumLat float64
+	for acct, p := range props {
+		lat, lon, ok := parseLatLon(p.Latitude, p.Longitude)
+		if !ok {
+			continue
+		}
+		g.byAcct[acct] = spatialPoint{Acct: acct, Lat: lat, Lon: lon}
+		sumLat += lat
+	}
+	meanLat := 0.0
+	if len(g.byAcct) > 0 {
+		meanLat = sumLat / float64(len(g.byAcct))
+	}
+	g.latStep = spatialCellMiles / milesPerDegreeLat
+	g.lonStep = spatialCellMiles / (milesPerDegreeLat * math.Cos(meanLat*math.Pi/180))
+
+	for _, pt := range g.byAcct {
+		c := g.cell(pt.Lat, pt.Lon)
+		g.cells[c] = append(g.cells[c], pt)
+	}
+	return g
+}
+
+func (g *gridIndex) cell(lat, lon float64) [2]int {
+	return [2]int{int(math.Floor(lat / g.latStep)), int(math.Floor(lon / g.lonStep))}
+}
+
+// location returns the indexed coordinates of an account.
+func (g *gridIndex) location(acct string) (float64, float64, bool) {
+	pt, ok := g.byAcct[acct]
+	return pt.Lat, pt.Lon, ok
+}
+
+// within returns every indexed parcel no more than miles from (lat, lon), unordered.
+func (g *gridIndex) within(lat, lon, miles float64) []spatialHit {
+	dLat := miles / milesPerDegreeLat
+	dLon := miles / (milesPerDegreeLat * math.Cos(lat*math.Pi/180))
+	lo := g.cell(lat-dLat, lon-dLon)
+	hi := g.cell(lat+dLat, lon+dLon)
+
+	var hits []spatialHit
+	for r := lo[0]; r <= hi[0]; r++ {
+		for c := lo[1]; c <= hi[1]; c++ {
+			for _, pt := range g.cells[[2]int{r, c}] {
+				if d := distanceMiles(lat, lon, pt.Lat, pt.Lon); d <= miles {
+					hits = append(hits, spatialHit{spatialPoint: pt, Miles: d})
+				}
+			}
+		}
+	}
+	return hits
+}
+
+// nearest returns up to k parcels closest to (lat, lon) within maxMiles, nearest first.
+// The search radius doubles until k points are found; any point among the k nearest
+// must then lie inside that radius, so the result is exact.
+func (g *gridIndex) nearest(lat, lon float64, k int, maxMiles float64) []spatialHit {
+	var hits []spatialHit
+	for r := spatialCellMiles; ; r *= 2 {
+		if r > maxMiles {
+			r = maxMiles
+		}
+		hits = g.within(lat, lon, r)
+		if len(hits) >= k || r >= maxMiles {
+			break
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Miles < hits[j].Miles })
+	if len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
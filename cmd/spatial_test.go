@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// spatialFixture scatters parcels around downtown and adds a row of points lying exactly
+// on grid cell edges.
+func spatialFixture() map[string]Property {
+	props := make(map[string]Property)
+	add := func(lat, lon float64) {
+		acct := fmt.Sprintf("%05d", len(props))
+		props[acct] = Property{AccountNum: acct, Latitude: fmt.Sprintf("%.9f", lat), Longitude: fmt.Sprintf("%.9f", lon)}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		add(32.70+rng.Float64()*0.1, -97.40+rng.Float64()*0.1)
+	}
+	g := newGridIndex(props)
+	for i := 0; i < 20; i++ {
+		lat := float64(int(32.75/g.latStep)+i) * g.latStep
+		lon := float64(int(-97.35/g.lonStep)-i) * g.lonStep
+		add(lat, lon)
+	}
+	return props
+}
+
+// bruteForce returns every point within miles of (lat, lon), nearest first.
+func bruteForce(g *gridIndex, lat, lon, miles float64) []spatialHit {
+	var hits []spatialHit
+	for _, pt := range g.byAcct {
+		if d := distanceMiles(lat, lon, pt.Lat, pt.Lon); d <= miles {
+			hits = append(hits, spatialHit{spatialPoint: pt, Miles: d})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Miles < hits[j].Miles })
+	return hits
+}
+
+func TestGridIndexWithinAndNearest(t *testing.T) {
+	props := spatialFixture()
+	g := newGridIndex(props)
+	edge := g.byAcct[fmt.Sprintf("%05d", 2005)] // a point on a cell corner
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		miles    float64
+		k        int
+	}{
+		{"small radius", 32.75, -97.35, 0.3, 5},
+		{"several cells", 32.73, -97.37, 0.9, 40},
+		{"query on a cell corner", edge.Lat, edge.Lon, 0.3, 10},
+		{"radius on a cell edge", edge.Lat, edge.Lon, spatialCellMiles, 25},
+		{"fewer than k in range", 32.75, -97.35, 0.2, 500},
+		{"outside the data", 33.5, -96.0, 2, 3},
+	}
+	for _, tt := range tests {
+		want := bruteForce(g, tt.lat, tt.lon, tt.miles)
+
+		got := g.within(tt.lat, tt.lon, tt.miles)
+		if len(got) != len(want) {
+			t.Errorf("%s: within found %d points, brute force %d", tt.name, len(got), len(want))
+		}
+
+		near := g.nearest(tt.lat, tt.lon, tt.k, tt.miles)
+		if len(want) > tt.k {
+			want = want[:tt.k]
+		}
+		if len(near) != len(want) {
+			t.Errorf("%s: nearest returned %d points, want %d", tt.name, len(near), len(want))
+			continue
+		}
+		for i := range near {
+			if near[i].Miles != want[i].Miles {
+				t.Errorf("%s: nearest[%d] at %g mi, want %g mi", tt.name, i, near[i].Miles, want[i].Miles)
+				break
+			}
+		}
+	}
+}
@@ -1,10 +1,9 @@
 package main
 
-// WGS-84 → Texas North-Central (EPSG:2276) Lambert Conformal Conic in US-survey feet.
-// Matches the parameters in ADM_ZONING.prj exactly, but implemented in pure Go – no CGO.
-// Formula follows EPSG guidance for LCC two-standard-parallel with e≠0.
-
-import "math"
+// WGS-84 ↔ Texas North-Central (EPSG:2276) Lambert Conformal Conic in US-survey feet.
+// This is the working coordinate system: every zoning layer is reprojected into it at
+// load time (see loadZoningShapefile), and parcel coordinates are converted into it for
+// lookups.
 
 const (
 	spFalseEasting  = 1968500.0
@@ -15,62 +14,20 @@ const (
 	lon0Deg         = -98.5             // central meridian
 
 	ftPerMeter = 3.2808333333333334 // US survey foot
-	semiMajorM = 6378137.0          // NAD83 semi-major axis (metres)
-	e2         = 0.00669438002290   // NAD83 eccentricity squared
-)
-
-var (
-	n    float64
-	F    float64
-	rho0 float64
 )
 
-func init() {
-	phi1 := phi1Deg * math.Pi / 180
-	phi2 := phi2Deg * math.Pi / 180
-	phi0 := phi0Deg * math.Pi / 180
-
-	const e2 = 0.00669438002290 // NAD83 eccentricity squared
-
-	m := func(phi float64) float64 {
-		return math.Cos(phi) / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
-	}
-
-	t := func(phi float64) float64 {
-		e := math.Sqrt(e2)
-		return math.Tan(math.Pi/4-phi/2) / math.Pow((1-e*math.Sin(phi))/(1+e*math.Sin(phi)), e/2)
-	}
-
-	m1 := m(phi1)
-	m2 := m(phi2)
-	t1 := t(phi1)
-	t2 := t(phi2)
-	t0 := t(phi0)
-
-	n = math.Log(m1/m2) / math.Log(t1/t2)
-
-	aFt := semiMajorM * ftPerMeter
-	F = m1 / (n * math.Pow(t1, n))
-	rho0 = aFt * F * math.Pow(t0, n)
-}
+// txNorthCentral is the working projection on the NAD83 (GRS 80) ellipsoid.
+var txNorthCentral = newLambertConformalConic(grs80, phi0Deg, lon0Deg, phi1Deg, phi2Deg, 1,
+	spFalseEasting, spFalseNorthing, 1/ftPerMeter)
 
 // wgs84ToTxNC converts latitude/longitude in decimal degrees (WGS-84) to
 // State-Plane North-Central Texas Lambert feet. It returns (northingFT, eastingFT)
 // which correspond to (lat, lon) ordering used in the zoningFeature rings.
 func wgs84ToTxNC(latDeg, lonDeg float64) (northingFt, eastingFt float64) {
-	phi := latDeg * math.Pi / 180
-	lambda := lonDeg * math.Pi / 180
-	lambda0 := lon0Deg * math.Pi / 180
-
-	e := math.Sqrt(e2)
-	t := math.Tan(math.Pi/4-phi/2) / math.Pow((1-e*math.Sin(phi))/(1+e*math.Sin(phi)), e/2)
-	rho := semiMajorM * ftPerMeter * F * math.Pow(t, n)
-	theta := n * (lambda - lambda0)
-
-	xFt := rho*math.Sin(theta) + spFalseEasting
-	yFt := rho0 - rho*math.Cos(theta) + spFalseNorthing
+	return txNorthCentral.forward(latDeg, lonDeg)
+}
 
-	eastingFt = xFt
-	northingFt = yFt
-	return
+// txNCToWGS84 is the inverse of wgs84ToTxNC.
+func txNCToWGS84(northingFt, eastingFt float64) (latDeg, lonDeg float64) {
+	return txNorthCentral.inverse(northingFt, eastingFt)
 }
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ---------------- Zoning upside ----------------
+
+// Downtown Fort Worth, the reference point for distance rankings.
+const (
+	downtownLat = 32.760089
+	downtownLon = -97.319828
+)
+
+// upsideResult is a parcel whose zoning would allow more homes than it holds today.
+type upsideResult struct {
+	Property
+	Existing  int     // dwelling units on the parcel now; 0 for a vacant lot
+	Potential int     // units the most permissive base district would allow
+	District  string  // dictionary key of that district
+	Reason    string  // what the extra units come from, e.g. "duplex" or "lot split ×2"
+	Land      float64 // appraised land value
+	LotSqFt   float64
+	Distance  float64 // miles from downtown
+	Score     float64 // 0-100, higher is a better redevelopment prospect
+}
+
+// existingUnits estimates how many dwelling units a parcel holds from its state use code,
+// falling back to the site class description. ok is false for parcels that are neither
+// housing nor a vacant lot.
+func existingUnits(p Property) (units int, ok bool) {
+	switch strings.ToUpper(strings.TrimSpace(p.StateUseCode)) {
+	case "A1", "A2", "E1":
+		return 1, true
+	case "B2":
+		return 2, true
+	case "B3":
+		return 3, true
+	case "B4":
+		return 4, true
+	case "B1":
+		return 5, true // apartments: at least five units, the exact count isn't published
+	case "C1":
+		return 0, true
+	}
+	descr := strings.ToLower(p.SiteClassDescr)
+	switch {
+	case strings.Contains(descr, "vacant"):
+		return 0, true
+	case strings.Contains(descr, "single family"), strings.Contains(descr, "mobile home"):
+		return 1, true
+	case strings.Contains(descr, "duplex"):
+		return 2, true
+	case strings.Contains(descr, "triplex"):
+		return 3, true
+	case strings.Contains(descr, "fourplex"), strings.Contains(descr, "quadplex"):
+		return 4, true
+	}
+	return 0, false
+}
+
+// unitsLabel names a unit count the way listings do.
+func unitsLabel(units int) string {
+	switch units {
+	case 0:
+		return "vacant"
+	case 1:
+		return "SF"
+	case 2:
+		return "duplex"
+	default:
+		return strconv.Itoa(units) + " units"
+	}
+}
+
+// gain is the number of units the parcel could add. A vacant lot counts as holding one
+// unit, since building the one house its zoning allows is not redevelopment.
+func (r upsideResult) gain() int {
+	return r.Potential - max(r.Existing, 1)
+}
+
+// upsideReason explains where a district's extra units come from.
+func upsideReason(d zoningDistrict, lotSqFt float64, existing int) string {
+	if d.MaxUnitsPerAcre == 0 && d.MinLotSqFt > 0 && lotSqFt >= 2*d.MinLotSqFt {
+		return fmt.Sprintf("lot split ×%d", int(lotSqFt/d.MinLotSqFt))
+	}
+	if d.permits(useMultifamily) && existing < 3 {
+		return residentialUseNames[useMultifamily]
+	}
+	if d.permits(useDuplex) && existing < 2 {
+		return residentialUseNames[useDuplex]
+	}
+	return "density"
+}
+
+// findUpside returns housing and vacant parcels matching f whose base zoning would allow at
+// least minGain more units than they hold now, as measured by gain. Parcels are ranked by
+// score, the average percentile of land value, lot size and closeness to downtown.
+func findUpside(y *yearData, f parcelFilter, minGain int) []upsideResult {
+	var results []upsideResult
+	for _, p := range f.parcels(y) {
+		existing, ok := existingUnits(p)
+		if !ok {
+			continue
+		}
+		lot, _ := lotSqFt(p)
+		r := upsideResult{Property: p, Existing: existing, LotSqFt: lot}
+		for _, code := range p.zoning.Base {
+			key, d, ok := lookupZoningDistrict(code)
+			if !ok {
+				continue
+			}
+			if units := d.maxUnits(lot); units > r.Potential {
+				r.Potential, r.District = units, key
+				r.Reason = upsideReason(d, lot, existing)
+			}
+		}
+		if r.gain() < minGain {
+			continue
+		}
+		lat, lon, ok := y.spatial.location(p.AccountNum)
+		if !ok {
+			continue
+		}
+		r.Distance = distanceMiles(downtownLat, downtownLon, lat, lon)
+		r.Land, _ = parseDollar(p.LandValue)
+		results = append(results, r)
+	}
+
+	land := make([]float64, len(results))
+	lots := make([]float64, len(results))
+	near := make([]float64, len(results))
+	for i, r := range results {
+		land[i], lots[i], near[i] = r.Land, r.LotSqFt, -r.Distance
+	}
+	landPct, lotPct, nearPct := percentileRanks(land), percentileRanks(lots), percentileRanks(near)
+	for i := range results {
+		results[i].Score = (landPct[i] + lotPct[i] + nearPct[i]) / 3
+	}
+	sortUpside(results, "score")
+	return results
+}
+
+// percentileRanks returns, for each value, the percentage of the other values below it.
+// Ties share the rank of their lowest position.
+func percentileRanks(vals []float64) []float64 {
+	ranks := make([]float64, len(vals))
+	if len(vals) < 2 {
+		for i := range ranks {
+			ranks[i] = 100
+		}
+		return ranks
+	}
+	idx := make([]int, len(vals))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return vals[idx[a]] < vals[idx[b]] })
+	below := 0
+	for pos, i := range idx {
+		if pos > 0 && vals[i] != vals[idx[pos-1]] {
+			below = pos
+		}
+		ranks[i] = 100 * float64(below) / float64(len(vals)-1)
+	}
+	return ranks
+}
+
+// upsideSorts are the orderings accepted by sort=.
+var upsideSorts = []string{"score", "land", "sqft", "dist", "units"}
+
+// sortUpside orders results by the named key, best first.
+func sortUpside(results []upsideResult, by string) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch by {
+		case "land":
+			if a.Land != b.Land {
+				return a.Land > b.Land
+			}
+		case "sqft":
+			if a.LotSqFt != b.LotSqFt {
+				return a.LotSqFt > b.LotSqFt
+			}
+		case "dist":
+			if a.Distance != b.Distance {
+				return a.Distance < b.Distance
+			}
+		case "units":
+			if ga, gb := a.gain(), b.gain(); ga != gb {
+				return ga > gb
+			}
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.SitusAddress < b.SitusAddress
+	})
+}
+
+// showUpside parses "upside [min=<units>] [sort=score|land|sqft|dist|units] [filters]" and
+// lists parcels zoned for more housing than they hold.
+func showUpside(args string, hist *history) {
+	minGain := 1
+	sortBy := "score"
+	f, err := parseFilterArgs(args, func(key, val string) (bool, error) {
+		switch key {
+		case "min":
+			v, err := strconv.Atoi(val)
+			if err != nil || v < 1 {
+				return true, fmt.Errorf("min=: invalid number of additional units %q", val)
+			}
+			minGain = v
+			return true, nil
+		case "sort":
+			val = strings.ToLower(val)
+			for _, s := range upsideSorts {
+				if val == s {
+					sortBy = val
+					return true, nil
+				}
+			}
+			return true, fmt.Errorf("sort=: unknown order %q (use %s)", val, strings.Join(upsideSorts, ", "))
+		}
+		return false, nil
+	})
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Usage: upside [min=<units>] [sort=score|land|sqft|dist|units] [sub=|zip=|city=|isd=|near=|owntype=|occ=|zone=|allows=|units=]")
+		return
+	}
+	if len(zoningFeatures) == 0 {
+		fmt.Println("No zoning layers loaded; upside needs zoning to compare against.")
+		return
+	}
+
+	start := time.Now()
+	results := findUpside(hist.current(), f, minGain)
+	sortUpside(results, sortBy)
+	fmt.Printf("\nFound %d parcels zoned for %d+ more units in %s, by %s (%v)\n",
+		len(results), minGain, f, sortBy, time.Since(start).Truncate(time.Millisecond))
+
+	var lines []string
+	var queries []string
+	for _, r := range results {
+		line := fmt.Sprintf("%-40s | %-7s → %3d units | %-6s %-14s | Land $%11s | %9s sf | %4.1f mi | score %3.0f",
+			r.SitusAddress, unitsLabel(r.Existing), r.Potential, r.District, r.Reason,
+			formatThousands(r.Land), formatThousands(r.LotSqFt), r.Distance, r.Score)
+		lines = append(lines, line)
+		queries = append(queries, "acct="+r.AccountNum)
+		fmt.Println(line)
+	}
+	fmt.Println("Use ↑/↓ and Enter for details, Esc to exit.")
+	interactiveSelect(queries, lines, hist, true)
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestExistingUnits(t *testing.T) {
+	tests := []struct {
+		code, descr string
+		want        int
+		ok          bool
+	}{
+		{"A1", "", 1, true},
+		{"B2", "", 2, true},
+		{"C1", "", 0, true},
+		{"", "Residential - Duplex", 2, true},
+		{"", "Vacant Lot", 0, true},
+		{"F1", "Commercial", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := existingUnits(Property{StateUseCode: tt.code, SiteClassDescr: tt.descr})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("existingUnits(%q, %q) = %d, %v, want %d, %v", tt.code, tt.descr, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPercentileRanks(t *testing.T) {
+	got := percentileRanks([]float64{30, 10, 20, 20, 40})
+	want := []float64{75, 0, 25, 25, 100}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("percentileRanks = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestUpsideReason(t *testing.T) {
+	codes := defaultZoningCodes()
+	tests := []struct {
+		district string
+		lot      float64
+		existing int
+		want     string
+	}{
+		{"A-5", 12000, 1, "lot split ×2"},
+		{"B", 6000, 1, "duplex"},
+		{"C", 20000, 1, "multifamily"},
+	}
+	for _, tt := range tests {
+		if got := upsideReason(codes[tt.district], tt.lot, tt.existing); got != tt.want {
+			t.Errorf("upsideReason(%s, %g, %d) = %q, want %q", tt.district, tt.lot, tt.existing, got, tt.want)
+		}
+	}
+}
+
+func TestSortUpsideUnitsMatchesGain(t *testing.T) {
+	results := []upsideResult{
+		{Property: Property{SitusAddress: "1 VACANT"}, Existing: 0, Potential: 3}, // gain 2
+		{Property: Property{SitusAddress: "2 HOUSE"}, Existing: 1, Potential: 4},  // gain 3
+	}
+	sortUpside(results, "units")
+	if results[0].SitusAddress != "2 HOUSE" {
+		t.Errorf("sort=units put %q first, want the larger gain", results[0].SitusAddress)
+	}
+}
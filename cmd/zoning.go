@@ -1,154 +1,218 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"math"
 	"path/filepath"
-
-	shp "github.com/jonas-p/go-shp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 )
 
 // zoningFeature represents a polygon (possibly multi-part) from the ADM_ZONING
 // shapefile together with its associated attribute table values.
 type zoningFeature struct {
-	Parts  [][][2]float64    // Each part is a closed ring of [lat, lon] points
-	Attrs  map[string]string // DBF attribute values keyed by field name
-	MinLat float64
-	MinLon float64
-	MaxLat float64
-	MaxLon float64
+	Layer   string // name of the shapefile the feature came from
+	Overlay bool   // true for overlay districts, false for base zoning
+	polygon
+	Attrs map[string]string // DBF attribute values keyed by field name
+}
+
+// zoningLayer is one zoning shapefile under data/<Name>/<Name>.shp.
+type zoningLayer struct {
+	Name    string
+	Overlay bool // overlay districts apply on top of the base zone rather than replacing it
 }
 
+// zoningLayers lists the base zoning layer and any supplemental layers (e.g. overlay
+// districts, PDs). Add additional shapefiles here and they will all be searched.
+var zoningLayers = []zoningLayer{
+	{Name: "ADM_ZONING"},
+	{Name: "ADM_ZONING_OVERLAY_DISTRICTS", Overlay: true},
+}
+
+// zoningCodeFields are the DBF fields that may hold a feature's zoning or district code,
+// in order of preference. DBF field names are truncated to 10 characters.
+var zoningCodeFields = []string{"ZONING", "BASE_ZONIN", "ZONE_CODE", "OVERLAY", "OVERLAY_NA", "DISTRICT", "NAME"}
+
 // Global slice containing all zoning polygons loaded at program start.
 var zoningFeatures []zoningFeature
 
-// initZoning loads the base zoning layer and any supplemental layers (e.g.
-// overlay districts, PDs).  Add additional shapefile directories in the list
-// below and they will all be searched.
-func initZoning() error {
-	layers := []struct {
-		dir  string
-		file string
-	}{
-		{"ADM_ZONING", "ADM_ZONING.shp"},
-		{"ADM_ZONING_OVERLAY_DISTRICTS", "ADM_ZONING_OVERLAY_DISTRICTS.shp"},
-	}
+// zoningIndex is an R-tree over the bounding boxes of zoningFeatures; search results
+// are indexes into zoningFeatures. It must be rebuilt with indexZoningFeatures whenever
+// zoningFeatures changes.
+var zoningIndex *rtree
 
-	for _, l := range layers {
-		shpPath := filepath.Join("data", l.dir, l.file)
+// initZoning loads every layer in zoningLayers. A layer that fails to load is reported
+// but does not prevent the others from being used.
+func initZoning() error {
+	var errs []error
+	for _, l := range zoningLayers {
+		shpPath := filepath.Join("data", l.Name, l.Name+".shp")
 		feats, err := loadZoningShapefile(shpPath)
 		if err != nil {
-			return fmt.Errorf("load zoning shapefile %s: %w", shpPath, err)
+			errs = append(errs, fmt.Errorf("load zoning shapefile %s: %w", shpPath, err))
+			continue
+		}
+		for i := range feats {
+			feats[i].Layer = l.Name
+			feats[i].Overlay = l.Overlay
 		}
 		zoningFeatures = append(zoningFeatures, feats...)
 	}
-	return nil
+	indexZoningFeatures()
+	return errors.Join(errs...)
+}
+
+// indexZoningFeatures rebuilds zoningIndex from zoningFeatures.
+func indexZoningFeatures() {
+	boxes := make([]bbox, len(zoningFeatures))
+	for i, z := range zoningFeatures {
+		boxes[i] = z.bbox
+	}
+	zoningIndex = newRTree(boxes)
 }
 
 // loadZoningShapefile reads the shapefile at the given path and converts it to
 // an in-memory slice of zoningFeature structs.
 func loadZoningShapefile(path string) ([]zoningFeature, error) {
-	r, err := shp.Open(path)
+	polys, attrs, err := loadPolygonShapefile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer r.Close()
+	features := make([]zoningFeature, len(polys))
+	for i, p := range polys {
+		features[i] = zoningFeature{polygon: p, Attrs: attrs[i]}
+	}
+	return features, nil
+}
 
-	fields := r.Fields()
+// findZoningFeatures returns every zoning polygon, from every layer, that contains
+// the given lat/lon. Callers use each feature's Layer and Overlay to tell the base
+// zone from overlay districts.
+func findZoningFeatures(lat, lon float64) []zoningFeature {
+	var hits []int
+	zoningIndex.searchPoint(lat, lon, func(i int) {
+		if zoningFeatures[i].contains(lat, lon) {
+			hits = append(hits, i)
+		}
+	})
+	// Report matches in load order so base layers come before overlays.
+	sort.Ints(hits)
+	matches := make([]zoningFeature, len(hits))
+	for i, h := range hits {
+		matches[i] = zoningFeatures[h]
+	}
+	return matches
+}
 
-	var features []zoningFeature
-	for r.Next() {
-		idx, shape := r.Shape()
-		poly, ok := shape.(*shp.Polygon)
-		if !ok {
-			// Skip non-polygon geometries (shouldn't exist in zoning layer)
-			continue
+// code returns the feature's zoning or district code, or "" if none of
+// zoningCodeFields is populated.
+func (z zoningFeature) code() string {
+	for _, f := range zoningCodeFields {
+		if v := strings.TrimSpace(z.Attrs[f]); v != "" {
+			return v
 		}
+	}
+	return ""
+}
 
-		// Split the flat points slice into parts.
-		numParts := len(poly.Parts)
-		parts := make([][][2]float64, numParts)
+// parcelZoning is the zoning that applies to one parcel with the base zone and the
+// overlay districts kept apart. Base normally has one entry; a parcel on a district
+// boundary can match more than one.
+type parcelZoning struct {
+	Located  bool // false when the parcel has no coordinates or no zoning layer is loaded
+	Base     []string
+	Overlays []string
+}
 
-		// Track bounding box while iterating.
-		minLat, minLon := math.MaxFloat64, math.MaxFloat64
-		maxLat, maxLon := -math.MaxFloat64, -math.MaxFloat64
+// zoningForProperty returns the zoning attached to p by attachZoning. ok is false when
+// the parcel could not be placed on the zoning map.
+func zoningForProperty(p Property) (z parcelZoning, ok bool) {
+	return p.zoning, p.zoning.Located
+}
 
-		for partIdx := 0; partIdx < numParts; partIdx++ {
-			start := poly.Parts[partIdx]
-			end := int32(len(poly.Points))
-			if partIdx+1 < numParts {
-				end = poly.Parts[partIdx+1]
-			}
-			ring := make([][2]float64, int(end-start))
-			j := 0
-			for i := start; i < end; i++ {
-				pt := poly.Points[i]
-				ring[j] = [2]float64{pt.Y, pt.X} // lat, lon
-				if pt.Y < minLat {
-					minLat = pt.Y
-				}
-				if pt.Y > maxLat {
-					maxLat = pt.Y
-				}
-				if pt.X < minLon {
-					minLon = pt.X
-				}
-				if pt.X > maxLon {
-					maxLon = pt.X
-				}
-				j++
-			}
-			parts[partIdx] = ring
+// lookupZoning finds every zoning feature at the parcel's coordinates.
+func lookupZoning(p Property) parcelZoning {
+	var z parcelZoning
+	latDeg, lonDeg, ok := parseLatLon(p.Latitude, p.Longitude)
+	if !ok || len(zoningFeatures) == 0 {
+		return z
+	}
+	z.Located = true
+	latFt, lonFt := wgs84ToTxNC(latDeg, lonDeg)
+	seen := make(map[string]bool)
+	for _, f := range findZoningFeatures(latFt, lonFt) {
+		code := f.code()
+		if code == "" || seen[f.Layer+"|"+code] {
+			continue
 		}
-
-		attrs := make(map[string]string)
-		for i, f := range fields {
-			attrs[f.String()] = r.ReadAttribute(idx, i)
+		seen[f.Layer+"|"+code] = true
+		if f.Overlay {
+			z.Overlays = append(z.Overlays, code)
+		} else {
+			z.Base = append(z.Base, code)
 		}
-
-		features = append(features, zoningFeature{
-			Parts:  parts,
-			Attrs:  attrs,
-			MinLat: minLat,
-			MinLon: minLon,
-			MaxLat: maxLat,
-			MaxLon: maxLon,
-		})
 	}
-	return features, nil
+	return z
 }
 
-// findZoningAttributes returns the attribute map for the first zoning polygon
-// that contains the given lat/lon. The second return value is true if a match
-// was found.
-func findZoningAttributes(lat, lon float64) (map[string]string, bool) {
-	for _, z := range zoningFeatures {
-		if lat < z.MinLat || lat > z.MaxLat || lon < z.MinLon || lon > z.MaxLon {
-			continue // quick bbox reject
-		}
-		for _, ring := range z.Parts {
-			if pointInPolygon(lat, lon, ring) {
-				return z.Attrs, true
+// attachZoning computes the zoning of every parcel in the year, spread across all CPUs.
+// Zoning comes from the shapefiles rather than the TAD exports, so it is never stored in
+// the snapshot; call ensureZoning rather than this directly.
+func (y *yearData) attachZoning() {
+	accts := make([]string, 0, len(y.ByAcct))
+	for acct := range y.ByAcct {
+		accts = append(accts, acct)
+	}
+	zones := make([]parcelZoning, len(accts))
+	workers := runtime.NumCPU()
+	chunk := (len(accts) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(accts); lo += chunk {
+		hi := min(lo+chunk, len(accts))
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				zones[i] = lookupZoning(y.ByAcct[accts[i]])
 			}
-		}
+		}(lo, hi)
 	}
-	return nil, false
+	wg.Wait()
+	for i, acct := range accts {
+		p := y.ByAcct[acct]
+		p.zoning = zones[i]
+		y.ByAcct[acct] = p
+	}
+}
+
+// ensureZoning attaches zoning to the year's parcels the first time it is needed, so
+// only the years actually looked at pay for it.
+func (y *yearData) ensureZoning() {
+	y.zoningOnce.Do(y.attachZoning)
 }
 
-// pointInPolygon implements the ray-casting algorithm for testing whether a
-// point is inside a polygon. The polygon must be closed (first == last) but we
-// don't require that here since shapefile rings are closed.
-func pointInPolygon(lat, lon float64, ring [][2]float64) bool {
-	inside := false
-	j := len(ring) - 1
-	for i := 0; i < len(ring); i++ {
-		yi, xi := ring[i][0], ring[i][1]
-		yj, xj := ring[j][0], ring[j][1]
-		intersect := ((yi > lat) != (yj > lat)) && (lon < (xj-xi)*(lat-yi)/(yj-yi)+xi)
-		if intersect {
-			inside = !inside
+// lines renders the zoning as label/value pairs: a "Zoning" line for each base zone,
+// followed by what the district allows when the code is in the zoning dictionary, and
+// one "Overlay" line per overlay district.
+func (z parcelZoning) lines() [][2]string {
+	var out [][2]string
+	for _, code := range z.Base {
+		_, d, ok := lookupZoningDistrict(code)
+		if !ok {
+			out = append(out, [2]string{"Zoning", code})
+			continue
 		}
-		j = i
+		out = append(out, [2]string{"Zoning", code + " – " + d.Description})
+		out = append(out, [2]string{"Allows", d.summary()})
+	}
+	if len(z.Base) == 0 {
+		out = append(out, [2]string{"Zoning", "none found"})
+	}
+	for _, o := range z.Overlays {
+		out = append(out, [2]string{"Overlay", o})
 	}
-	return inside
+	return out
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ---------------- Zoning district dictionary ----------------
+
+// zoningCodesFile holds the plain-English meaning and residential standards of each
+// zoning district. It is created with the Fort Worth defaults below on first run and
+// can be edited to correct a standard or add another city's districts.
+var zoningCodesFile = filepath.Join("data", "zoning_codes.json")
+
+// Residential uses a district may permit.
+const (
+	useSingleFamily = "sf"
+	useDuplex       = "duplex"
+	useMultifamily  = "mf"
+	useADU          = "adu"
+)
+
+// residentialUseNames are the labels shown for each use.
+var residentialUseNames = map[string]string{
+	useSingleFamily: "single-family",
+	useDuplex:       "duplex",
+	useMultifamily:  "multifamily",
+	useADU:          "ADU",
+}
+
+// zoningDistrict describes one zoning district. Zero standards mean "not set".
+type zoningDistrict struct {
+	Description     string   `json:"description"`
+	Uses            []string `json:"uses"` // permitted residential uses: sf, duplex, mf, adu
+	MinLotSqFt      float64  `json:"min_lot_sqft,omitempty"`
+	MinLotWidthFt   float64  `json:"min_lot_width_ft,omitempty"`
+	MaxUnitsPerAcre float64  `json:"max_units_per_acre,omitempty"`
+}
+
+// defaultZoningCodes are the Fort Worth districts. Standards are summarized from the
+// zoning ordinance and are a screening aid only; check the ordinance before relying on
+// one for a specific parcel.
+func defaultZoningCodes() map[string]zoningDistrict {
+	sf := []string{useSingleFamily}
+	sfADU := []string{useSingleFamily, useADU}
+	return map[string]zoningDistrict{
+		"AG":    {Description: "Agricultural", Uses: sf, MinLotSqFt: 43560, MinLotWidthFt: 100},
+		"A-43":  {Description: "One-family, 1 acre lots", Uses: sfADU, MinLotSqFt: 43560, MinLotWidthFt: 100},
+		"A-21":  {Description: "One-family, half-acre lots", Uses: sfADU, MinLotSqFt: 21780, MinLotWidthFt: 100},
+		"A-10":  {Description: "One-family, 10,000 sf lots", Uses: sfADU, MinLotSqFt: 10000, MinLotWidthFt: 60},
+		"A-7.5": {Description: "One-family, 7,500 sf lots", Uses: sfADU, MinLotSqFt: 7500, MinLotWidthFt: 60},
+		"A-5":   {Description: "One-family, 5,000 sf lots", Uses: sfADU, MinLotSqFt: 5000, MinLotWidthFt: 50},
+		"AR":    {Description: "One-family restricted, small lots", Uses: sf, MinLotSqFt: 3500, MinLotWidthFt: 35},
+		"B":     {Description: "Two-family", Uses: []string{useSingleFamily, useDuplex}, MinLotSqFt: 5000, MinLotWidthFt: 50},
+		"R1":    {Description: "Zero lot line / cluster", Uses: sf, MinLotSqFt: 4500, MinLotWidthFt: 40},
+		"R2":    {Description: "Townhouse / cluster", Uses: []string{useSingleFamily, useDuplex}, MinLotSqFt: 2500, MaxUnitsPerAcre: 24},
+		"CR":    {Description: "Low density multifamily", Uses: []string{useSingleFamily, useDuplex, useMultifamily}, MaxUnitsPerAcre: 16},
+		"C":     {Description: "Medium density multifamily", Uses: []string{useSingleFamily, useDuplex, useMultifamily}, MaxUnitsPerAcre: 24},
+		"D":     {Description: "High density multifamily", Uses: []string{useMultifamily}, MaxUnitsPerAcre: 32},
+		"UR":    {Description: "Urban residential", Uses: []string{useSingleFamily, useDuplex, useMultifamily}, MaxUnitsPerAcre: 60},
+		"MU-1":  {Description: "Low intensity mixed-use", Uses: []string{useSingleFamily, useDuplex, useMultifamily}, MaxUnitsPerAcre: 40},
+		"MU-2":  {Description: "High intensity mixed-use", Uses: []string{useMultifamily}, MaxUnitsPerAcre: 100},
+		"ER":    {Description: "Neighborhood commercial restricted"},
+		"E":     {Description: "Neighborhood commercial"},
+		"F":     {Description: "General commercial"},
+		"FR":    {Description: "General commercial restricted"},
+		"G":     {Description: "Intensive commercial"},
+		"H":     {Description: "Central business district", Uses: []string{useMultifamily}},
+		"I":     {Description: "Light industrial"},
+		"J":     {Description: "Medium industrial"},
+		"K":     {Description: "Heavy industrial"},
+		"PD":    {Description: "Planned development (uses set by the PD ordinance)"},
+	}
+}
+
+// zoningCodes is the dictionary in use; loadZoningCodes replaces the defaults with the
+// contents of zoningCodesFile.
+var zoningCodes = defaultZoningCodes()
+
+// loadZoningCodes reads zoningCodesFile over the defaults. Districts in the file replace
+// the default entry of the same code; districts it does not mention keep their defaults.
+func loadZoningCodes() error {
+	codes := defaultZoningCodes()
+	if err := loadJSONConfig(zoningCodesFile, &codes); err != nil {
+		return err
+	}
+	for code, d := range codes {
+		for _, u := range d.Uses {
+			if _, ok := residentialUseNames[u]; !ok {
+				return fmt.Errorf("%s: district %s: unknown use %q (use sf, duplex, mf or adu)", zoningCodesFile, code, u)
+			}
+		}
+	}
+	zoningCodes = codes
+	return nil
+}
+
+// lookupZoningDistrict finds the dictionary entry for a zoning code as it appears in the
+// shapefile. Codes often carry suffixes ("A-5/HC", "PD 1234"), so after an exact match it
+// tries the part before the first "/" or space. There is no prefix matching: "CF" is a
+// different district from "C", and an unknown code is better than a wrong one.
+func lookupZoningDistrict(code string) (string, zoningDistrict, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if d, ok := zoningCodes[code]; ok {
+		return code, d, true
+	}
+	if i := strings.IndexAny(code, "/ "); i > 0 {
+		if d, ok := zoningCodes[code[:i]]; ok {
+			return code[:i], d, true
+		}
+	}
+	return "", zoningDistrict{}, false
+}
+
+// permits reports whether the district allows the residential use.
+func (d zoningDistrict) permits(use string) bool {
+	return slices.Contains(d.Uses, use)
+}
+
+// maxUnits estimates how many dwelling units a lot could legally hold: by density when
+// the district caps units per acre, otherwise one home per minimum lot (the lot-split
+// potential). It returns 0 when the district allows no housing or the lot is too small.
+func (d zoningDistrict) maxUnits(lotSqFt float64) int {
+	if len(d.Uses) == 0 || lotSqFt <= 0 || lotSqFt < d.MinLotSqFt {
+		return 0
+	}
+	if d.MaxUnitsPerAcre > 0 {
+		return max(1, int(math.Floor(lotSqFt/43560*d.MaxUnitsPerAcre)))
+	}
+	if d.MinLotSqFt > 0 {
+		units := int(lotSqFt / d.MinLotSqFt)
+		if d.permits(useDuplex) && !d.permits(useMultifamily) {
+			units *= 2
+		}
+		return units
+	}
+	return 1
+}
+
+// summary renders the district's uses and standards on one line, e.g.
+// "single-family, ADU | min lot 5,000 sf, 50 ft wide".
+func (d zoningDistrict) summary() string {
+	uses := "no residential uses"
+	if len(d.Uses) > 0 {
+		names := make([]string, len(d.Uses))
+		for i, u := range d.Uses {
+			names[i] = residentialUseNames[u]
+		}
+		uses = strings.Join(names, ", ")
+	}
+	var std []string
+	if d.MinLotSqFt > 0 {
+		lot := fmt.Sprintf("min lot %s sf", formatThousands(d.MinLotSqFt))
+		if d.MinLotWidthFt > 0 {
+			lot += fmt.Sprintf(", %.0f ft wide", d.MinLotWidthFt)
+		}
+		std = append(std, lot)
+	}
+	if d.MaxUnitsPerAcre > 0 {
+		std = append(std, fmt.Sprintf("max %g units/acre", d.MaxUnitsPerAcre))
+	}
+	if len(std) == 0 {
+		return uses
+	}
+	return uses + " | " + strings.Join(std, ", ")
+}
+
+// formatThousands renders a whole number with comma separators.
+func formatThousands(v float64) string {
+	s := fmt.Sprintf("%.0f", v)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// baseDistricts resolves every base zoning code of the parcel in the dictionary.
+func (z parcelZoning) baseDistricts() []zoningDistrict {
+	var out []zoningDistrict
+	for _, code := range z.Base {
+		if _, d, ok := lookupZoningDistrict(code); ok {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// parseResidentialUses parses a comma-separated allows= value such as "duplex,adu".
+func parseResidentialUses(val string) ([]string, error) {
+	var uses []string
+	for _, u := range strings.Split(val, ",") {
+		u = strings.ToLower(strings.TrimSpace(u))
+		if u == "" {
+			continue
+		}
+		if _, ok := residentialUseNames[u]; !ok {
+			known := make([]string, 0, len(residentialUseNames))
+			for k := range residentialUseNames {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("allows=: unknown use %q (use %s)", u, strings.Join(known, ", "))
+		}
+		uses = append(uses, u)
+	}
+	if len(uses) == 0 {
+		return nil, fmt.Errorf("allows= expects at least one use")
+	}
+	return uses, nil
+}
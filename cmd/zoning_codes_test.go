@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestLookupZoningDistrict(t *testing.T) {
+	saved := zoningCodes
+	defer func() { zoningCodes = saved }()
+	zoningCodes = defaultZoningCodes()
+
+	tests := []struct {
+		code string
+		want string // "" for unknown
+	}{
+		{"A-5", "A-5"},
+		{" a-5 ", "A-5"},
+		{"A-5/HC", "A-5"},
+		{"PD 1234", "PD"},
+		{"C", "C"},
+		{"CF", ""}, // community facilities, not medium density multifamily
+		{"A-5HC", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		key, _, ok := lookupZoningDistrict(tt.code)
+		if ok != (tt.want != "") || key != tt.want {
+			t.Errorf("lookupZoningDistrict(%q) = %q, %v, want %q", tt.code, key, ok, tt.want)
+		}
+	}
+}
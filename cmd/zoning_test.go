@@ -0,0 +1,102 @@
+package main
+
+import "testing"
+
+// square returns a closed ring of [lat, lon] points around the given bounds, clockwise
+// (an outer ring) or counter-clockwise (a hole) when viewed with lon east and lat north.
+func square(minLat, minLon, maxLat, maxLon float64, clockwise bool) [][2]float64 {
+	ring := [][2]float64{
+		{minLat, minLon},
+		{maxLat, minLon},
+		{maxLat, maxLon},
+		{minLat, maxLon},
+		{minLat, minLon},
+	}
+	if !clockwise {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return ring
+}
+
+// testFeature builds a zoningFeature from rings the same way loadZoningShapefile does.
+func testFeature(code string, rings ...[][2]float64) zoningFeature {
+	return zoningFeature{Attrs: map[string]string{"ZONING": code}, polygon: newPolygon(rings)}
+}
+
+func TestRingIsHole(t *testing.T) {
+	if ringIsHole(square(0, 0, 10, 10, true)) {
+		t.Error("clockwise ring classified as a hole")
+	}
+	if !ringIsHole(square(0, 0, 10, 10, false)) {
+		t.Error("counter-clockwise ring not classified as a hole")
+	}
+}
+
+func TestZoningFeatureContains(t *testing.T) {
+	// A 10x10 square with a 4x4 hole in the middle and a 1x1 island inside the hole.
+	donut := testFeature("R1",
+		square(0, 0, 10, 10, true),
+		square(3, 3, 7, 7, false),
+		square(4.5, 4.5, 5.5, 5.5, true),
+	)
+	// Two disjoint outer rings, the second with its own hole.
+	multi := testFeature("C",
+		square(0, 0, 2, 2, true),
+		square(20, 20, 30, 30, true),
+		square(24, 24, 26, 26, false),
+	)
+
+	tests := []struct {
+		name     string
+		feature  zoningFeature
+		lat, lon float64
+		want     bool
+	}{
+		{"solid part of donut", donut, 1, 1, true},
+		{"inside hole", donut, 4, 4, false},
+		{"island inside hole", donut, 5, 5, true},
+		{"outside bbox", donut, 11, 5, false},
+		{"first part of multipolygon", multi, 1, 1, true},
+		{"second part of multipolygon", multi, 22, 22, true},
+		{"hole in second part", multi, 25, 25, false},
+		{"between parts", multi, 10, 10, false},
+	}
+	for _, tt := range tests {
+		if got := tt.feature.contains(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("%s: contains(%g, %g) = %v, want %v", tt.name, tt.lat, tt.lon, got, tt.want)
+		}
+	}
+}
+
+func TestFindZoningFeaturesHole(t *testing.T) {
+	// A park zoned "PR" fills the hole in the surrounding "A-5" district.
+	saved := zoningFeatures
+	defer func() {
+		zoningFeatures = saved
+		indexZoningFeatures()
+	}()
+	zoningFeatures = []zoningFeature{
+		testFeature("A-5", square(0, 0, 10, 10, true), square(3, 3, 7, 7, false)),
+		testFeature("PR", square(3, 3, 7, 7, true)),
+	}
+	indexZoningFeatures()
+
+	for _, tt := range []struct {
+		lat, lon float64
+		want     string
+	}{
+		{1, 1, "A-5"},
+		{5, 5, "PR"},
+	} {
+		got := findZoningFeatures(tt.lat, tt.lon)
+		if len(got) != 1 || got[0].code() != tt.want {
+			var codes []string
+			for _, z := range got {
+				codes = append(codes, z.code())
+			}
+			t.Errorf("findZoningFeatures(%g, %g) = %v, want [%s]", tt.lat, tt.lon, codes, tt.want)
+		}
+	}
+}